@@ -13,9 +13,70 @@ import (
 )
 
 type Request struct {
-	Delay   int      `yaml:"delay"`
-	Headers []string `yaml:"headers"`
-	Timeout int      `yaml:"timeout"`
+	Delay            int                 `yaml:"delay"`
+	RateLimit        float64             `yaml:"rate-limit"`
+	RateLimitPerHost float64             `yaml:"rate-limit-per-host"`
+	Headers          []string            `yaml:"headers"`
+	HostHeaders      map[string][]string `yaml:"host-headers,omitempty"`
+	Timeout          int                 `yaml:"timeout"`
+	Retry            Retry               `yaml:"retry"`
+}
+
+type Retry struct {
+	Attempts int      `yaml:"attempts"`
+	Backoff  float64  `yaml:"backoff"`
+	Statuses []int    `yaml:"statuses"`
+	Errors   []string `yaml:"errors"`
+}
+
+type TLS struct {
+	Verify       bool          `yaml:"verify"`
+	CA           string        `yaml:"ca,omitempty"`
+	MinVersion   string        `yaml:"min-version,omitempty"`
+	ServerName   string        `yaml:"server-name,omitempty"`
+	Certificates []Certificate `yaml:"certificates,omitempty"`
+}
+
+type Certificate struct {
+	Host string `yaml:"host,omitempty"`
+	Cert string `yaml:"cert"`
+	Key  string `yaml:"key,omitempty"`
+}
+
+type ProxyRoute struct {
+	Hosts []string `yaml:"hosts"`
+	Proxy string   `yaml:"proxy"`
+}
+
+type DNS struct {
+	Resolve   []string `yaml:"resolve"`
+	Resolvers []string `yaml:"resolvers"`
+}
+
+type Login struct {
+	Steps   []LoginStep `yaml:"steps"`
+	Headers []string    `yaml:"headers,omitempty"`
+	Logout  Logout      `yaml:"logout,omitempty"`
+}
+
+type LoginStep struct {
+	Method  string         `yaml:"method,omitempty"`
+	URL     string         `yaml:"url"`
+	Headers []string       `yaml:"headers,omitempty"`
+	Body    string         `yaml:"body,omitempty"`
+	Extract []LoginExtract `yaml:"extract,omitempty"`
+}
+
+type LoginExtract struct {
+	Name   string `yaml:"name"`
+	Regex  string `yaml:"regex,omitempty"`
+	JSON   string `yaml:"json,omitempty"`
+	Header string `yaml:"header,omitempty"`
+}
+
+type Logout struct {
+	URL     string   `yaml:"url,omitempty"`
+	Markers []string `yaml:"markers,omitempty"`
 }
 
 type Optimization struct {
@@ -25,10 +86,17 @@ type Optimization struct {
 }
 
 type Configuration struct {
-	Version      string       `yaml:"version"`
-	Request      Request      `yaml:"request"`
-	Proxies      []string     `yaml:"proxies"`
-	Optimization Optimization `yaml:"optimization"`
+	Version       string              `yaml:"version"`
+	Request       Request             `yaml:"request"`
+	TLS           TLS                 `yaml:"tls"`
+	Proxies       []string            `yaml:"proxies"`
+	ProxyRotation string              `yaml:"proxy-rotation"`
+	ProxyPools    map[string][]string `yaml:"proxy-pools,omitempty"`
+	ProxyRoutes   []ProxyRoute        `yaml:"proxy-routes,omitempty"`
+	DNS           DNS                 `yaml:"dns"`
+	Sitemaps      []string            `yaml:"sitemaps"`
+	Login         *Login              `yaml:"login,omitempty"`
+	Optimization  Optimization        `yaml:"optimization"`
 }
 
 func (cfg *Configuration) Write(path string) (err error) {
@@ -100,8 +168,38 @@ __  _____ _ __ __ ___      _| |___ / _ __
 				fmt.Sprintf("%s: %s v%s (https://github.com/hueristiq/%s)", hqgohttpheader.UserAgent, NAME, VERSION, NAME),
 			},
 			Timeout: 10,
+			Retry: Retry{
+				Attempts: 3,
+				Backoff:  1,
+				Statuses: []int{429, 500, 502, 503, 504},
+				Errors:   []string{"timeout", "reset", "eof"},
+			},
+		},
+		Proxies:       []string{},
+		ProxyRotation: "round-robin",
+		DNS: DNS{
+			Resolve:   []string{},
+			Resolvers: []string{},
+		},
+		Sitemaps: []string{
+			"/sitemap.xml",
+			"/sitemap.xml.gz",
+			"/sitemap.txt",
+			"/sitemap_news.xml",
+			"/sitemap_index.xml",
+			"/sitemap-index.xml",
+			"/sitemapindex.xml",
+			"/sitemap-news.xml",
+			"/post-sitemap.xml",
+			"/page-sitemap.xml",
+			"/portfolio-sitemap.xml",
+			"/home_slider-sitemap.xml",
+			"/category-sitemap.xml",
+			"/author-sitemap.xml",
+			"/feed",
+			"/rss.xml",
+			"/atom.xml",
 		},
-		Proxies: []string{},
 		Optimization: Optimization{
 			Depth:       1,
 			Concurrency: 5,
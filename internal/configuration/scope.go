@@ -0,0 +1,28 @@
+package configuration
+
+import (
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Scope struct {
+	Hosts          []string `yaml:"hosts"`
+	ExcludePaths   []string `yaml:"exclude-paths"`
+	ExcludeRegexes []string `yaml:"exclude-regexes"`
+	Ports          []int    `yaml:"ports"`
+	Schemes        []string `yaml:"schemes"`
+}
+
+func ReadScope(path string) (scope Scope, err error) {
+	var data []byte
+
+	data, err = os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	err = yaml.Unmarshal(data, &scope)
+
+	return
+}
@@ -84,7 +84,25 @@ func (w *Writer) writeTXT(writer io.Writer, result xcrawl3r.Result) (err error)
 
 func (w *Writer) writeJSON(writer io.Writer, result xcrawl3r.Result) (err error) {
 	data := resultForJSONL{
-		URL: result.Value,
+		Type:          resultTypes[result.Type],
+		URL:           result.Value,
+		Source:        string(result.Source),
+		Referer:       result.Referer,
+		Depth:         result.Depth,
+		Tag:           result.Tag,
+		Attribute:     result.Attribute,
+		LastModified:  result.LastModified,
+		StatusCode:    result.StatusCode,
+		ContentType:   result.ContentType,
+		ContentLength: result.ContentLength,
+		Attempts:      result.Attempts,
+		Parameter:     result.Parameter,
+	}
+
+	if result.Form != nil {
+		data.Method = result.Form.Method
+		data.Enctype = result.Form.Enctype
+		data.Fields = result.Form.Fields
 	}
 
 	var dataJSONBytes []byte
@@ -110,7 +128,22 @@ func (w *Writer) writeJSON(writer io.Writer, result xcrawl3r.Result) (err error)
 type format string
 
 type resultForJSONL struct {
-	URL string `json:"url"`
+	Type          string               `json:"type,omitempty"`
+	URL           string               `json:"url"`
+	Source        string               `json:"source,omitempty"`
+	Referer       string               `json:"referer,omitempty"`
+	Depth         int                  `json:"depth,omitempty"`
+	Tag           string               `json:"tag,omitempty"`
+	Attribute     string               `json:"attribute,omitempty"`
+	LastModified  string               `json:"last_modified,omitempty"`
+	StatusCode    int                  `json:"status_code,omitempty"`
+	ContentType   string               `json:"content_type,omitempty"`
+	ContentLength int64                `json:"content_length,omitempty"`
+	Attempts      int                  `json:"attempts,omitempty"`
+	Method        string               `json:"method,omitempty"`
+	Enctype       string               `json:"enctype,omitempty"`
+	Fields        []xcrawl3r.FormField `json:"fields,omitempty"`
+	Parameter     string               `json:"parameter,omitempty"`
 }
 
 const (
@@ -118,7 +151,18 @@ const (
 	formatTXT   format = "TXT"
 )
 
-var ErrNoFilePathSpecified = errors.New("no file path specified")
+var (
+	resultTypes = map[xcrawl3r.ResultType]string{
+		xcrawl3r.ResultURL:           "url",
+		xcrawl3r.ResultFile:          "file",
+		xcrawl3r.ResultForm:          "form",
+		xcrawl3r.ResultParameter:     "parameter",
+		xcrawl3r.ResultOutOfScope:    "out-of-scope",
+		xcrawl3r.ResultHostParameter: "host-parameter",
+	}
+
+	ErrNoFilePathSpecified = errors.New("no file path specified")
+)
 
 func NewWriter() (writter *Writer) {
 	writter = &Writer{
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hueristiq/xcrawl3r/pkg/xcrawl3r"
+)
+
+func TestWriterParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		result xcrawl3r.Result
+		TXT    string
+		JSONL  string
+	}{
+		{
+			name: "parameter",
+			result: xcrawl3r.Result{
+				Type:      xcrawl3r.ResultParameter,
+				Value:     "https://example.com/search",
+				Source:    xcrawl3r.ResultSourceHref,
+				Referer:   "https://example.com/",
+				Depth:     1,
+				Parameter: "q",
+			},
+			TXT:   "https://example.com/search\n",
+			JSONL: `{"type":"parameter","url":"https://example.com/search","source":"href","referer":"https://example.com/","depth":1,"parameter":"q"}` + "\n",
+		},
+		{
+			name: "host parameter",
+			result: xcrawl3r.Result{
+				Type:      xcrawl3r.ResultHostParameter,
+				Value:     "https://example.com",
+				Source:    xcrawl3r.ResultSourceJSON,
+				Parameter: "id",
+			},
+			TXT:   "https://example.com\n",
+			JSONL: `{"type":"host-parameter","url":"https://example.com","source":"json","parameter":"id"}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var TXT, JSONL bytes.Buffer
+
+			writer := NewWriter()
+
+			if err := writer.Write(&TXT, test.result); err != nil {
+				t.Fatal(err)
+			}
+
+			writer.SetFormatToJSONL()
+
+			if err := writer.Write(&JSONL, test.result); err != nil {
+				t.Fatal(err)
+			}
+
+			if TXT.String() != test.TXT {
+				t.Errorf("Write() TXT = %q, want %q", TXT.String(), test.TXT)
+			}
+
+			if JSONL.String() != test.JSONL {
+				t.Errorf("Write() JSONL = %q, want %q", JSONL.String(), test.JSONL)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hueristiq/xcrawl3r/internal/configuration"
+	"github.com/hueristiq/xcrawl3r/pkg/xcrawl3r"
+)
+
+type WARCWriter struct {
+	mutex sync.Mutex
+
+	path      string
+	extension string
+	compress  bool
+	maxSize   int64
+
+	file   *os.File
+	size   int64
+	index  int
+	infoID string
+	err    error
+	closed bool
+}
+
+// NOTE: Request and response records of an exchange are written together, to the same file.
+func (w *WARCWriter) Record(exchange xcrawl3r.Exchange) {
+	w.mutex.Lock()
+
+	defer w.mutex.Unlock()
+
+	if w.closed || w.err != nil {
+		return
+	}
+
+	if w.maxSize > 0 && w.size >= w.maxSize {
+		if w.err = w.rotate(); w.err != nil {
+			return
+		}
+	}
+
+	date := exchange.Date.UTC().Format(time.RFC3339Nano)
+	URL := exchange.Request.URL.String()
+
+	responseID := warcRecordID()
+
+	response := []string{
+		"WARC-Type: response",
+		"WARC-Record-ID: " + responseID,
+		"WARC-Warcinfo-ID: " + w.infoID,
+		"WARC-Date: " + date,
+		"WARC-Target-URI: " + URL,
+		"WARC-Payload-Digest: " + warcDigest(exchange.ResponseBody),
+		"Content-Type: application/http;msgtype=response",
+	}
+
+	if exchange.ServerIPAddress != "" {
+		response = append(response, "WARC-IP-Address: "+exchange.ServerIPAddress)
+	}
+
+	// NOTE: Bodies cut at the record body limit are marked as such.
+	if exchange.ResponseBodyTruncated {
+		response = append(response, "WARC-Truncated: length")
+	}
+
+	if w.err = w.write(response, warcResponse(exchange.Response, exchange.ResponseBody)); w.err != nil {
+		return
+	}
+
+	request := []string{
+		"WARC-Type: request",
+		"WARC-Record-ID: " + warcRecordID(),
+		"WARC-Warcinfo-ID: " + w.infoID,
+		"WARC-Concurrent-To: " + responseID,
+		"WARC-Date: " + date,
+		"WARC-Target-URI: " + URL,
+		"Content-Type: application/http;msgtype=request",
+	}
+
+	if exchange.RequestBodyTruncated {
+		request = append(request, "WARC-Truncated: length")
+	}
+
+	w.err = w.write(request, warcRequest(exchange.Request, exchange.RequestBody))
+}
+
+func (w *WARCWriter) Close() (err error) {
+	w.mutex.Lock()
+
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	w.closed = true
+
+	err = w.err
+
+	if w.file != nil {
+		if closeErr := w.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	return
+}
+
+func (w *WARCWriter) rotate() (err error) {
+	if w.file != nil {
+		if err = w.file.Close(); err != nil {
+			return
+		}
+	}
+
+	path := w.path + w.extension
+
+	// NOTE: Rotated files are numbered, `crawl.warc.gz` being written as `crawl-00000.warc.gz`, ...
+	if w.maxSize > 0 {
+		path = fmt.Sprintf("%s-%05d%s", w.path, w.index, w.extension)
+	}
+
+	w.index++
+
+	w.file, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+
+	w.size = 0
+	w.infoID = warcRecordID()
+
+	info := []string{
+		"WARC-Type: warcinfo",
+		"WARC-Record-ID: " + w.infoID,
+		"WARC-Date: " + time.Now().UTC().Format(time.RFC3339Nano),
+		"WARC-Filename: " + filepath.Base(path),
+		"Content-Type: application/warc-fields",
+	}
+
+	fields := fmt.Sprintf("software: %s/%s\r\nformat: WARC File Format 1.1\r\n", configuration.NAME, configuration.VERSION)
+
+	err = w.write(info, []byte(fields))
+
+	return
+}
+
+// NOTE: With compression, each record is its own gzip member, as WARC readers expect.
+func (w *WARCWriter) write(headers []string, block []byte) (err error) {
+	var record bytes.Buffer
+
+	record.WriteString("WARC/1.1\r\n")
+
+	for _, header := range headers {
+		record.WriteString(header + "\r\n")
+	}
+
+	fmt.Fprintf(&record, "WARC-Block-Digest: %s\r\n", warcDigest(block))
+	fmt.Fprintf(&record, "Content-Length: %d\r\n\r\n", len(block))
+
+	record.Write(block)
+	record.WriteString("\r\n\r\n")
+
+	counter := &countingWriter{
+		writer: w.file,
+	}
+
+	defer func() {
+		w.size += counter.written
+	}()
+
+	if !w.compress {
+		_, err = counter.Write(record.Bytes())
+
+		return
+	}
+
+	gz := gzip.NewWriter(counter)
+
+	if _, err = gz.Write(record.Bytes()); err != nil {
+		return
+	}
+
+	err = gz.Close()
+
+	return
+}
+
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = w.writer.Write(p)
+
+	w.written += int64(n)
+
+	return
+}
+
+func warcRequest(req *http.Request, body []byte) (message []byte) {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "%s %s HTTP/1.1\r\n", req.Method, req.URL.RequestURI())
+
+	host := req.Host
+
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	fmt.Fprintf(&buffer, "Host: %s\r\n", host)
+
+	req.Header.Write(&buffer)
+
+	buffer.WriteString("\r\n")
+	buffer.Write(body)
+
+	message = buffer.Bytes()
+
+	return
+}
+
+// NOTE: Bodies decompressed by the transport are archived decompressed, their
+// Content-Encoding and Content-Length headers having been removed with it.
+func warcResponse(res *http.Response, body []byte) (message []byte) {
+	var buffer bytes.Buffer
+
+	fmt.Fprintf(&buffer, "%s %s\r\n", res.Proto, res.Status)
+
+	res.Header.Write(&buffer)
+
+	buffer.WriteString("\r\n")
+	buffer.Write(body)
+
+	message = buffer.Bytes()
+
+	return
+}
+
+func warcRecordID() (ID string) {
+	var b [16]byte
+
+	rand.Read(b[:])
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	ID = fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+
+	return
+}
+
+func warcDigest(data []byte) (digest string) {
+	sum := sha1.Sum(data)
+
+	digest = "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+
+	return
+}
+
+func NewWARCWriter(path string, compress bool, maxSize int64) (writer *WARCWriter, err error) {
+	if path == "" {
+		err = ErrNoFilePathSpecified
+
+		return
+	}
+
+	extension := ".warc"
+
+	if compress {
+		extension += ".gz"
+	}
+
+	path = strings.TrimSuffix(strings.TrimSuffix(path, ".gz"), ".warc")
+
+	directory := filepath.Dir(path)
+
+	if directory != "" {
+		if _, err = os.Stat(directory); os.IsNotExist(err) {
+			err = os.MkdirAll(directory, 0o750)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	writer = &WARCWriter{
+		path:      path,
+		extension: extension,
+		compress:  compress,
+		maxSize:   maxSize,
+	}
+
+	err = writer.rotate()
+
+	return
+}
@@ -0,0 +1,64 @@
+package output
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hueristiq/xcrawl3r/pkg/xcrawl3r"
+)
+
+func TestWARCWriterTruncated(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestTruncated  bool
+		responseTruncated bool
+		truncated         int
+	}{
+		{"complete", false, false, 0},
+		{"truncated response", false, true, 1},
+		{"truncated request & response", true, true, 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "crawl.warc")
+
+			writer, err := NewWARCWriter(path, false, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "https://example.com/search", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			writer.Record(xcrawl3r.Exchange{
+				Date:                  time.Now(),
+				Request:               req,
+				RequestBody:           []byte("q=1&"),
+				RequestBodyTruncated:  test.requestTruncated,
+				Response:              &http.Response{Proto: "HTTP/1.1", Status: "200 OK", Header: http.Header{}},
+				ResponseBody:          []byte("0123"),
+				ResponseBodyTruncated: test.responseTruncated,
+			})
+
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if truncated := strings.Count(string(data), "WARC-Truncated: length\r\n"); truncated != test.truncated {
+				t.Errorf("Record() wrote %d WARC-Truncated headers, want %d", truncated, test.truncated)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+type Wordlist struct {
+	mutex sync.Mutex
+	words map[string]struct{}
+}
+
+func (w *Wordlist) Add(word string) {
+	w.mutex.Lock()
+
+	defer w.mutex.Unlock()
+
+	w.words[word] = struct{}{}
+}
+
+func (w *Wordlist) Save(path string) (err error) {
+	if path == "" {
+		err = ErrNoFilePathSpecified
+
+		return
+	}
+
+	directory := filepath.Dir(path)
+
+	if directory != "" {
+		if _, err = os.Stat(directory); os.IsNotExist(err) {
+			err = os.MkdirAll(directory, 0o750)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	w.mutex.Lock()
+
+	words := make([]string, 0, len(w.words))
+
+	for word := range w.words {
+		words = append(words, word)
+	}
+
+	w.mutex.Unlock()
+
+	slices.Sort(words)
+
+	var file *os.File
+
+	file, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	bw := bufio.NewWriter(file)
+
+	for _, word := range words {
+		fmt.Fprintln(bw, word)
+	}
+
+	if err = bw.Flush(); err != nil {
+		return
+	}
+
+	err = file.Sync()
+
+	return
+}
+
+func NewWordlist() (wordlist *Wordlist) {
+	wordlist = &Wordlist{
+		words: map[string]struct{}{},
+	}
+
+	return
+}
@@ -0,0 +1,35 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWordlistSave(t *testing.T) {
+	wordlist := NewWordlist()
+
+	for _, word := range []string{"page", "id", "q", "id", "page"} {
+		wordlist.Add(word)
+	}
+
+	path := filepath.Join(t.TempDir(), "nested", "parameters.txt")
+
+	if err := wordlist.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "id\npage\nq\n"; string(content) != want {
+		t.Errorf("Save() wrote %q, want %q", content, want)
+	}
+
+	if err := wordlist.Save(""); !errors.Is(err, ErrNoFilePathSpecified) {
+		t.Errorf("Save(\"\") error = %v, want %v", err, ErrNoFilePathSpecified)
+	}
+}
@@ -0,0 +1,181 @@
+package xcrawl3r
+
+import (
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type patterns struct {
+	variants int
+
+	mutex sync.Mutex
+	seen  map[string]map[string]struct{}
+}
+
+func (p *patterns) Admit(URL string) (admitted bool) {
+	pattern, err := urlPattern(URL)
+	if err != nil {
+		admitted = true
+
+		return
+	}
+
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	variants, ok := p.seen[pattern]
+	if !ok {
+		variants = map[string]struct{}{}
+
+		p.seen[pattern] = variants
+	}
+
+	if _, ok := variants[URL]; ok || len(variants) >= p.variants {
+		return
+	}
+
+	variants[URL] = struct{}{}
+
+	admitted = true
+
+	return
+}
+
+func newPatterns(variants int) (p *patterns) {
+	if variants < 1 {
+		variants = 1
+	}
+
+	p = &patterns{
+		variants: variants,
+		seen:     map[string]map[string]struct{}{},
+	}
+
+	return
+}
+
+// NOTE: Trailing slashes are only trimmed when asked to, as `/a` and `/a/` may be distinct resources.
+func canonicalize(URL string, trimTrailingSlash bool) (canonical string, err error) {
+	var parsed *url.URL
+
+	parsed, err = url.Parse(strings.TrimSpace(URL))
+	if err != nil {
+		return
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+
+	if parsed.Host != "" {
+		var host string
+
+		host, err = normalizeHost(parsed.Hostname())
+		if err != nil {
+			return
+		}
+
+		port := parsed.Port()
+
+		switch {
+		case port != "" && port != defaultPorts[parsed.Scheme]:
+			host = net.JoinHostPort(host, port)
+		case strings.Contains(host, ":"):
+			host = "[" + host + "]"
+		}
+
+		parsed.Host = host
+	}
+
+	parsed.Fragment = ""
+	parsed.RawFragment = ""
+
+	if parsed.Path == "" && parsed.Opaque == "" {
+		parsed.Path = "/"
+	}
+
+	if strings.Contains(parsed.Path, "/.") {
+		cleaned := path.Clean(parsed.Path)
+
+		if strings.HasSuffix(parsed.Path, "/") && cleaned != "/" {
+			cleaned += "/"
+		}
+
+		parsed.Path = cleaned
+		parsed.RawPath = ""
+	}
+
+	// NOTE: Trimmed on the escaped path, so that escaped slashes (`%2F`) are kept.
+	if escaped := parsed.EscapedPath(); trimTrailingSlash && len(escaped) > 1 && strings.HasSuffix(escaped, "/") {
+		escaped = strings.TrimRight(escaped, "/")
+
+		if escaped == "" {
+			escaped = "/"
+		}
+
+		if unescaped, err := url.PathUnescape(escaped); err == nil {
+			parsed.Path, parsed.RawPath = unescaped, escaped
+		}
+	}
+
+	if parsed.RawQuery != "" {
+		parameters := slices.DeleteFunc(strings.Split(parsed.RawQuery, "&"), func(parameter string) bool {
+			return parameter == ""
+		})
+
+		slices.SortStableFunc(parameters, func(a, b string) int {
+			a, _, _ = strings.Cut(a, "=")
+			b, _, _ = strings.Cut(b, "=")
+
+			return strings.Compare(a, b)
+		})
+
+		parsed.RawQuery = strings.Join(parameters, "&")
+	}
+
+	parsed.ForceQuery = false
+
+	canonical = parsed.String()
+
+	return
+}
+
+func urlPattern(URL string) (pattern string, err error) {
+	var parsed *url.URL
+
+	parsed, err = url.Parse(URL)
+	if err != nil {
+		return
+	}
+
+	segments := strings.Split(strings.TrimSuffix(parsed.EscapedPath(), "/"), "/")
+
+	for i, segment := range segments {
+		if patternSegmentRegex.MatchString(segment) {
+			segments[i] = "{}"
+		}
+	}
+
+	names := []string{}
+
+	for parameter := range strings.SplitSeq(parsed.RawQuery, "&") {
+		name, _, _ := strings.Cut(parameter, "=")
+
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	pattern = parsed.Scheme + "://" + parsed.Host + strings.Join(segments, "/") + "?" + strings.Join(names, "&")
+
+	return
+}
+
+// NOTE: numbers, UUIDs and hex hashes.
+var patternSegmentRegex = regexp.MustCompile(`^(?:\d+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
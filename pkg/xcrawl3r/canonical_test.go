@@ -0,0 +1,127 @@
+package xcrawl3r
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		URL       string
+		canonical string
+	}{
+		{"HTTP://Example.COM", "http://example.com/"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"http://example.com:80/a", "http://example.com/a"},
+		{"http://example.com:8080/a", "http://example.com:8080/a"},
+		{"https://example.com/a#section", "https://example.com/a"},
+		{"https://example.com/a?b=2&a=1", "https://example.com/a?a=1&b=2"},
+		{"https://example.com/a?b=2&&a=1&", "https://example.com/a?a=1&b=2"},
+		{"https://example.com/a?x=2&x=1", "https://example.com/a?x=2&x=1"},
+		{"https://example.com/a?", "https://example.com/a"},
+		{"https://example.com/a/./b/../c", "https://example.com/a/c"},
+		{"https://example.com/a/b/../", "https://example.com/a/"},
+		{"https://bücher.example/", "https://xn--bcher-kva.example/"},
+		{"http://[2001:DB8::1]:80/", "http://[2001:db8::1]/"},
+		{"http://[2001:db8::1]:8080/", "http://[2001:db8::1]:8080/"},
+		{"  https://example.com/a  ", "https://example.com/a"},
+	}
+
+	for _, test := range tests {
+		canonical, err := canonicalize(test.URL, false)
+		if err != nil {
+			t.Errorf("canonicalize(%q) error: %v", test.URL, err)
+
+			continue
+		}
+
+		if canonical != test.canonical {
+			t.Errorf("canonicalize(%q) = %q, want %q", test.URL, canonical, test.canonical)
+		}
+	}
+}
+
+func TestCanonicalizeTrailingSlash(t *testing.T) {
+	tests := []struct {
+		URL       string
+		canonical string
+		trimmed   string
+	}{
+		{"https://example.com/a/", "https://example.com/a/", "https://example.com/a"},
+		{"https://example.com/a//", "https://example.com/a//", "https://example.com/a"},
+		{"https://example.com/a/b/?q=1", "https://example.com/a/b/?q=1", "https://example.com/a/b?q=1"},
+		{"https://example.com/a%2F/", "https://example.com/a%2F/", "https://example.com/a%2F"},
+		{"https://example.com/a/b/../", "https://example.com/a/", "https://example.com/a"},
+		{"https://example.com/a", "https://example.com/a", "https://example.com/a"},
+		{"https://example.com/", "https://example.com/", "https://example.com/"},
+		{"https://example.com//", "https://example.com//", "https://example.com/"},
+		{"https://example.com", "https://example.com/", "https://example.com/"},
+	}
+
+	for _, test := range tests {
+		canonical, err := canonicalize(test.URL, false)
+		if err != nil {
+			t.Fatalf("canonicalize(%q) error: %v", test.URL, err)
+		}
+
+		if canonical != test.canonical {
+			t.Errorf("canonicalize(%q) = %q, want %q", test.URL, canonical, test.canonical)
+		}
+
+		trimmed, err := canonicalize(test.URL, true)
+		if err != nil {
+			t.Fatalf("canonicalize(%q) error: %v", test.URL, err)
+		}
+
+		if trimmed != test.trimmed {
+			t.Errorf("canonicalize(%q) trimming trailing slashes = %q, want %q", test.URL, trimmed, test.trimmed)
+		}
+	}
+}
+
+func TestURLPattern(t *testing.T) {
+	tests := []struct {
+		URL     string
+		pattern string
+	}{
+		{"https://example.com/users/42", "https://example.com/users/{}?"},
+		{"https://example.com/users/42/", "https://example.com/users/{}?"},
+		{"https://example.com/users/me", "https://example.com/users/me?"},
+		{"https://example.com/o/123e4567-e89b-12d3-a456-426614174000", "https://example.com/o/{}?"},
+		{"https://example.com/f/0123456789abcdef0123", "https://example.com/f/{}?"},
+		{"https://example.com/f/cafe", "https://example.com/f/cafe?"},
+		{"https://example.com/search?q=a&page=2", "https://example.com/search?page&q"},
+		{"https://example.com/search?page=3&q=b&q=c", "https://example.com/search?page&q"},
+	}
+
+	for _, test := range tests {
+		pattern, err := urlPattern(test.URL)
+		if err != nil {
+			t.Errorf("urlPattern(%q) error: %v", test.URL, err)
+
+			continue
+		}
+
+		if pattern != test.pattern {
+			t.Errorf("urlPattern(%q) = %q, want %q", test.URL, pattern, test.pattern)
+		}
+	}
+}
+
+func TestPatternsAdmit(t *testing.T) {
+	p := newPatterns(2)
+
+	tests := []struct {
+		URL      string
+		admitted bool
+	}{
+		{"https://example.com/users/1", true},
+		{"https://example.com/users/1", false},
+		{"https://example.com/users/2", true},
+		{"https://example.com/users/3", false},
+		{"https://example.com/users/me", true},
+	}
+
+	for _, test := range tests {
+		if admitted := p.Admit(test.URL); admitted != test.admitted {
+			t.Errorf("Admit(%q) = %v, want %v", test.URL, admitted, test.admitted)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package xcrawl3r
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+type cookieJar struct {
+	jar *cookiejar.Jar
+
+	mutex   sync.Mutex
+	cookies map[string]jarCookie
+
+	persist     string
+	persistLock sync.Mutex
+}
+
+// NOTE: With a persist path, the jar is saved on every change, so a crawl stopped without a
+// clean exit resumes with its session. Failures are left to the final save, on Close, to report.
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return
+	}
+
+	j.mutex.Lock()
+
+	changed := false
+
+	defer func() {
+		j.mutex.Unlock()
+
+		if changed && j.persist != "" {
+			j.persistLock.Lock()
+
+			defer j.persistLock.Unlock()
+
+			_ = j.Save(j.persist)
+		}
+	}()
+
+	now := time.Now()
+
+	for _, cookie := range cookies {
+		c := jarCookie{
+			Cookie:   *cookie,
+			HostOnly: cookie.Domain == "",
+		}
+
+		c.Domain = host
+
+		if !c.HostOnly {
+			domain := strings.TrimPrefix(strings.ToLower(cookie.Domain), ".")
+
+			// NOTE: Mirrors the jar, which rejects cookies for domains the host is not within.
+			if host != domain && !strings.HasSuffix(host, "."+domain) {
+				continue
+			}
+
+			c.Domain = domain
+		}
+
+		if c.Path == "" || !strings.HasPrefix(c.Path, "/") {
+			c.Path = defaultCookiePath(u)
+		}
+
+		if cookie.MaxAge > 0 {
+			c.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+		}
+
+		key := c.key()
+
+		existing, ok := j.cookies[key]
+
+		if cookie.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(now)) {
+			if ok {
+				delete(j.cookies, key)
+
+				changed = true
+			}
+
+			continue
+		}
+
+		if ok && existing.line() == c.line() {
+			continue
+		}
+
+		j.cookies[key] = c
+
+		changed = true
+	}
+}
+
+func (j *cookieJar) Cookies(u *url.URL) (cookies []*http.Cookie) {
+	cookies = j.jar.Cookies(u)
+
+	return
+}
+
+func (j *cookieJar) Import(r io.Reader) (err error) {
+	var data []byte
+
+	data, err = io.ReadAll(r)
+	if err != nil {
+		return
+	}
+
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, utf8BOM))
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		err = j.importHAR(data)
+
+		return
+	}
+
+	err = j.importNetscape(data)
+
+	return
+}
+
+func (j *cookieJar) importNetscape(data []byte) (err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+
+		httpOnly := strings.HasPrefix(text, netscapeHTTPOnlyPrefix)
+
+		text = strings.TrimPrefix(text, netscapeHTTPOnlyPrefix)
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+
+		if len(fields) < 7 {
+			err = fmt.Errorf("error parsing cookies line %d: %w", line, ErrInvalidCookie)
+
+			return
+		}
+
+		domain := fields[0]
+
+		cookie := &http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     fields[2],
+			Secure:   strings.EqualFold(fields[3], "TRUE"),
+			HttpOnly: httpOnly,
+		}
+
+		if expires, err := strconv.ParseInt(fields[4], 10, 64); err == nil && expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+
+		if strings.EqualFold(fields[1], "TRUE") {
+			cookie.Domain = domain
+		}
+
+		j.set(strings.TrimPrefix(domain, "."), cookie)
+	}
+
+	err = scanner.Err()
+
+	return
+}
+
+func (j *cookieJar) importHAR(data []byte) (err error) {
+	var har harLog
+
+	if err = json.Unmarshal(data, &har); err != nil {
+		return
+	}
+
+	for _, entry := range har.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || u.Host == "" {
+			continue
+		}
+
+		for _, c := range entry.Request.Cookies {
+			cookie := &http.Cookie{
+				Name:  c.Name,
+				Value: c.Value,
+				Path:  "/",
+			}
+
+			j.SetCookies(u, []*http.Cookie{cookie})
+		}
+
+		for _, c := range entry.Response.Cookies {
+			cookie := &http.Cookie{
+				Name:     c.Name,
+				Value:    c.Value,
+				Path:     c.Path,
+				Domain:   c.Domain,
+				Secure:   c.Secure,
+				HttpOnly: c.HTTPOnly,
+			}
+
+			if expires, err := time.Parse(time.RFC3339, c.Expires); err == nil {
+				cookie.Expires = expires
+			}
+
+			j.SetCookies(u, []*http.Cookie{cookie})
+		}
+	}
+
+	return
+}
+
+func (j *cookieJar) Export(w io.Writer) (err error) {
+	j.mutex.Lock()
+
+	cookies := make([]jarCookie, 0, len(j.cookies))
+
+	now := time.Now()
+
+	for _, cookie := range j.cookies {
+		if !cookie.Expires.IsZero() && cookie.Expires.Before(now) {
+			continue
+		}
+
+		cookies = append(cookies, cookie)
+	}
+
+	j.mutex.Unlock()
+
+	slices.SortFunc(cookies, func(a, b jarCookie) int {
+		return strings.Compare(a.key(), b.key())
+	})
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# Netscape HTTP Cookie File")
+
+	for _, cookie := range cookies {
+		fmt.Fprintln(bw, cookie.line())
+	}
+
+	err = bw.Flush()
+
+	return
+}
+
+// NOTE: Written to a temporary file renamed over the previous one, so a crash mid write does
+// not leave a truncated jar.
+func (j *cookieJar) Save(name string) (err error) {
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return
+	}
+
+	var file *os.File
+
+	file, err = os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return
+	}
+
+	defer os.Remove(file.Name())
+
+	if err = j.Export(file); err != nil {
+		file.Close()
+
+		return
+	}
+
+	if err = file.Close(); err != nil {
+		return
+	}
+
+	err = os.Rename(file.Name(), name)
+
+	return
+}
+
+func (j *cookieJar) set(host string, cookie *http.Cookie) {
+	u := &url.URL{
+		Scheme: "http",
+		Host:   host,
+		Path:   cookie.Path,
+	}
+
+	if cookie.Secure {
+		u.Scheme = "https"
+	}
+
+	j.SetCookies(u, []*http.Cookie{cookie})
+}
+
+type jarCookie struct {
+	http.Cookie
+
+	HostOnly bool
+}
+
+func (c jarCookie) key() (key string) {
+	key = c.Domain + ";" + c.Path + ";" + c.Name
+
+	return
+}
+
+func (c jarCookie) line() (line string) {
+	domain := c.Domain
+	includeSubdomains := "FALSE"
+
+	if !c.HostOnly {
+		domain = "." + domain
+		includeSubdomains = "TRUE"
+	}
+
+	if c.HttpOnly {
+		domain = netscapeHTTPOnlyPrefix + domain
+	}
+
+	secure := "FALSE"
+
+	if c.Secure {
+		secure = "TRUE"
+	}
+
+	var expires int64
+
+	if !c.Expires.IsZero() {
+		expires = c.Expires.Unix()
+	}
+
+	line = fmt.Sprintf("%s\t%s\t%s\t%s\t%d\t%s\t%s", domain, includeSubdomains, c.Path, secure, expires, c.Name, c.Value)
+
+	return
+}
+
+func newCookieJar() (j *cookieJar, err error) {
+	j = &cookieJar{
+		cookies: map[string]jarCookie{},
+	}
+
+	j.jar, err = cookiejar.New(&cookiejar.Options{
+		PublicSuffixList: publicsuffix.List,
+	})
+
+	return
+}
+
+func defaultCookiePath(u *url.URL) (p string) {
+	p = u.Path
+
+	if p == "" || !strings.HasPrefix(p, "/") || strings.Count(p, "/") == 1 {
+		p = "/"
+
+		return
+	}
+
+	p = path.Dir(p)
+
+	return
+}
+
+const netscapeHTTPOnlyPrefix = "#HttpOnly_"
+
+var ErrInvalidCookie = errors.New("invalid cookie")
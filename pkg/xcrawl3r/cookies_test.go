@@ -0,0 +1,200 @@
+package xcrawl3r
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCookieJarImportExport(t *testing.T) {
+	expires := time.Now().Add(24 * time.Hour).Unix()
+
+	tests := []struct {
+		name   string
+		input  string
+		output []string
+	}{
+		{
+			name: "netscape",
+			input: strings.Join([]string{
+				"# Netscape HTTP Cookie File",
+				"",
+				"example.com\tFALSE\t/\tFALSE\t0\tsession\tabc",
+				fmt.Sprintf(".example.com\tTRUE\t/\tTRUE\t%d\tprefs\tdark", expires),
+				"#HttpOnly_example.com\tFALSE\t/app\tFALSE\t0\ttoken\txyz",
+				"example.com\tFALSE\t/\tFALSE\t1\texpired\tgone",
+			}, "\n"),
+			output: []string{
+				"#HttpOnly_example.com\tFALSE\t/app\tFALSE\t0\ttoken\txyz",
+				fmt.Sprintf(".example.com\tTRUE\t/\tTRUE\t%d\tprefs\tdark", expires),
+				"example.com\tFALSE\t/\tFALSE\t0\tsession\tabc",
+			},
+		},
+		{
+			name: "har",
+			input: `{"log":{"entries":[{
+				"request":{"url":"https://example.com/login","cookies":[{"name":"a","value":"1"}]},
+				"response":{"cookies":[
+					{"name":"b","value":"2","path":"/","domain":".example.com","secure":true,"httpOnly":true},
+					{"name":"c","value":"3","path":"/","domain":"other.com"}
+				]}
+			}]}}`,
+			output: []string{
+				"#HttpOnly_.example.com\tTRUE\t/\tTRUE\t0\tb\t2",
+				"example.com\tFALSE\t/\tFALSE\t0\ta\t1",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jar, err := newCookieJar()
+			if err != nil {
+				t.Fatalf("newCookieJar() error: %v", err)
+			}
+
+			if err = jar.Import(strings.NewReader(test.input)); err != nil {
+				t.Fatalf("Import() error: %v", err)
+			}
+
+			var exported bytes.Buffer
+
+			if err = jar.Export(&exported); err != nil {
+				t.Fatalf("Export() error: %v", err)
+			}
+
+			first := exported.String()
+
+			lines := strings.Split(strings.TrimSpace(first), "\n")
+
+			if lines[0] != "# Netscape HTTP Cookie File" {
+				t.Errorf("Export() header = %q", lines[0])
+			}
+
+			output := lines[1:]
+
+			slices.Sort(output)
+
+			if !slices.Equal(output, test.output) {
+				t.Errorf("Export() = %q, want %q", output, test.output)
+			}
+
+			// NOTE: Exported jars import back to the same cookies.
+			reimported, err := newCookieJar()
+			if err != nil {
+				t.Fatalf("newCookieJar() error: %v", err)
+			}
+
+			if err = reimported.Import(&exported); err != nil {
+				t.Fatalf("Import() of exported jar error: %v", err)
+			}
+
+			var again bytes.Buffer
+
+			if err = reimported.Export(&again); err != nil {
+				t.Fatalf("Export() error: %v", err)
+			}
+
+			if again.String() != first {
+				t.Errorf("Export() after round trip = %q, want %q", again.String(), first)
+			}
+		})
+	}
+}
+
+func TestCookieJarImportInvalid(t *testing.T) {
+	jar, err := newCookieJar()
+	if err != nil {
+		t.Fatalf("newCookieJar() error: %v", err)
+	}
+
+	err = jar.Import(strings.NewReader("example.com\tFALSE\t/\n"))
+	if !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("Import() error = %v, want %v", err, ErrInvalidCookie)
+	}
+}
+
+func TestCookieJarCookies(t *testing.T) {
+	jar, err := newCookieJar()
+	if err != nil {
+		t.Fatalf("newCookieJar() error: %v", err)
+	}
+
+	input := strings.Join([]string{
+		"example.com\tFALSE\t/\tFALSE\t0\thost\t1",
+		".example.com\tTRUE\t/\tFALSE\t0\tdomain\t2",
+		"example.com\tFALSE\t/admin\tFALSE\t0\tadmin\t3",
+		"example.com\tFALSE\t/\tTRUE\t0\tsecure\t4",
+	}, "\n")
+
+	if err = jar.Import(strings.NewReader(input)); err != nil {
+		t.Fatalf("Import() error: %v", err)
+	}
+
+	tests := []struct {
+		URL   string
+		names []string
+	}{
+		{"http://example.com/", []string{"domain", "host"}},
+		{"https://example.com/", []string{"domain", "host", "secure"}},
+		{"http://example.com/admin/users", []string{"admin", "domain", "host"}},
+		{"http://www.example.com/", []string{"domain"}},
+		{"http://example.org/", nil},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.URL)
+		if err != nil {
+			t.Fatalf("url.Parse(%q) error: %v", test.URL, err)
+		}
+
+		var names []string
+
+		for _, cookie := range jar.Cookies(u) {
+			names = append(names, cookie.Name)
+		}
+
+		slices.Sort(names)
+
+		if !slices.Equal(names, test.names) {
+			t.Errorf("Cookies(%q) = %v, want %v", test.URL, names, test.names)
+		}
+	}
+}
+
+func TestCookieJarSetCookies(t *testing.T) {
+	jar, err := newCookieJar()
+	if err != nil {
+		t.Fatalf("newCookieJar() error: %v", err)
+	}
+
+	u, _ := url.Parse("https://app.example.com/account/settings")
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "session", Value: "1"},
+		{Name: "wide", Value: "2", Domain: "example.com", Path: "/"},
+		{Name: "foreign", Value: "3", Domain: "other.com"},
+	})
+
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "wide", Value: "", Domain: "example.com", Path: "/", MaxAge: -1},
+	})
+
+	var exported bytes.Buffer
+
+	if err = jar.Export(&exported); err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+
+	want := "# Netscape HTTP Cookie File\napp.example.com\tFALSE\t/account\tFALSE\t0\tsession\t1\n"
+
+	if exported.String() != want {
+		t.Errorf("Export() = %q, want %q", exported.String(), want)
+	}
+}
@@ -0,0 +1,166 @@
+package xcrawl3r
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+)
+
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+func (c *Crawler) dialer(dial dialFunc) (wrapped dialFunc) {
+	if len(c.resolves) == 0 {
+		wrapped = dial
+
+		return
+	}
+
+	wrapped = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			conn, err = dial(ctx, network, address)
+
+			return
+		}
+
+		IPs := c.resolve(host, port)
+
+		if len(IPs) == 0 {
+			conn, err = dial(ctx, network, address)
+
+			return
+		}
+
+		for _, IP := range IPs {
+			conn, err = dial(ctx, network, net.JoinHostPort(IP, port))
+			if err == nil {
+				return
+			}
+		}
+
+		return
+	}
+
+	return
+}
+
+// NOTE: Overrides are keyed by host:port, `*` matching any port, as in curl's --resolve.
+func (c *Crawler) resolve(host, port string) (IPs []string) {
+	host, err := normalizeHost(host)
+	if err != nil {
+		return
+	}
+
+	IPs, ok := c.resolves[net.JoinHostPort(host, port)]
+	if !ok {
+		IPs = c.resolves[net.JoinHostPort(host, "*")]
+	}
+
+	return
+}
+
+func parseResolves(entries []string) (resolves map[string][]string, err error) {
+	resolves = map[string][]string{}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		host, rest, _ := strings.Cut(entry, ":")
+		port, addresses, _ := strings.Cut(rest, ":")
+
+		if host == "" || port == "" || addresses == "" {
+			err = fmt.Errorf("error parsing resolve %s: %w", entry, ErrInvalidResolve)
+
+			return
+		}
+
+		host, err = normalizeHost(host)
+		if err != nil {
+			err = fmt.Errorf("error parsing resolve %s: %w", entry, err)
+
+			return
+		}
+
+		key := net.JoinHostPort(host, port)
+
+		for address := range strings.SplitSeq(addresses, ",") {
+			IP := net.ParseIP(strings.Trim(strings.TrimSpace(address), "[]"))
+			if IP == nil {
+				err = fmt.Errorf("error parsing resolve %s: %w", entry, ErrInvalidResolve)
+
+				return
+			}
+
+			resolves[key] = append(resolves[key], IP.String())
+		}
+	}
+
+	return
+}
+
+func newResolver(servers []string) (resolver *net.Resolver, err error) {
+	addresses := []string{}
+
+	for _, server := range servers {
+		server = strings.TrimSpace(server)
+
+		if server == "" {
+			continue
+		}
+
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+		}
+
+		host, _, _ := net.SplitHostPort(server)
+
+		if IP := net.ParseIP(host); IP == nil {
+			err = fmt.Errorf("error parsing resolver %s: %w", server, ErrInvalidResolver)
+
+			return
+		}
+
+		addresses = append(addresses, server)
+	}
+
+	if len(addresses) == 0 {
+		return
+	}
+
+	var next atomic.Uint64
+
+	dialer := &net.Dialer{}
+
+	// NOTE: Each lookup attempt goes to the next resolver, so retried lookups fail over across them.
+	resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (conn net.Conn, err error) {
+			start := next.Add(1)
+
+			for i := range addresses {
+				address := addresses[(int(start)+i)%len(addresses)]
+
+				conn, err = dialer.DialContext(ctx, network, address)
+				if err == nil {
+					return
+				}
+			}
+
+			return
+		},
+	}
+
+	return
+}
+
+var (
+	ErrInvalidResolve  = errors.New("invalid resolve, expected host:port:address")
+	ErrInvalidResolver = errors.New("invalid resolver, expected IP[:port]")
+)
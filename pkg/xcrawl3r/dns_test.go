@@ -0,0 +1,180 @@
+package xcrawl3r
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"net"
+	"slices"
+	"testing"
+)
+
+func TestParseResolves(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []string
+		resolves map[string][]string
+		err      error
+	}{
+		{
+			name:     "address",
+			entries:  []string{"example.com:443:127.0.0.1"},
+			resolves: map[string][]string{"example.com:443": {"127.0.0.1"}},
+		},
+		{
+			name:     "addresses",
+			entries:  []string{"example.com:443:127.0.0.1, [::1],10.0.0.1"},
+			resolves: map[string][]string{"example.com:443": {"127.0.0.1", "::1", "10.0.0.1"}},
+		},
+		{
+			name:     "any port",
+			entries:  []string{" EXAMPLE.com:*:127.0.0.1 ", "", "api.example.com:80:::1"},
+			resolves: map[string][]string{"example.com:*": {"127.0.0.1"}, "api.example.com:80": {"::1"}},
+		},
+		{
+			name:     "repeated",
+			entries:  []string{"example.com:443:127.0.0.1", "example.com:443:127.0.0.2"},
+			resolves: map[string][]string{"example.com:443": {"127.0.0.1", "127.0.0.2"}},
+		},
+		{
+			name:    "missing port",
+			entries: []string{"example.com"},
+			err:     ErrInvalidResolve,
+		},
+		{
+			name:    "missing address",
+			entries: []string{"example.com:443:"},
+			err:     ErrInvalidResolve,
+		},
+		{
+			name:    "hostname address",
+			entries: []string{"example.com:443:origin.example.com"},
+			err:     ErrInvalidResolve,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolves, err := parseResolves(test.entries)
+
+			if !errors.Is(err, test.err) {
+				t.Fatalf("parseResolves() error = %v, want %v", err, test.err)
+			}
+
+			if err == nil && !maps.EqualFunc(resolves, test.resolves, slices.Equal) {
+				t.Errorf("parseResolves() = %v, want %v", resolves, test.resolves)
+			}
+		})
+	}
+
+	if _, err := parseResolves([]string{"a..b:443:127.0.0.1"}); err == nil {
+		t.Errorf("parseResolves() with an invalid host error = nil, want error")
+	}
+}
+
+func TestCrawlerResolve(t *testing.T) {
+	resolves, err := parseResolves([]string{"example.com:443:127.0.0.1", "example.com:*:127.0.0.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Crawler{
+		resolves: resolves,
+	}
+
+	tests := []struct {
+		host string
+		port string
+		IPs  []string
+	}{
+		{"example.com", "443", []string{"127.0.0.1"}},
+		{"EXAMPLE.COM", "443", []string{"127.0.0.1"}},
+		{"example.com", "80", []string{"127.0.0.2"}},
+		{"example.org", "443", nil},
+	}
+
+	for _, test := range tests {
+		if IPs := c.resolve(test.host, test.port); !slices.Equal(IPs, test.IPs) {
+			t.Errorf("resolve(%s, %s) = %v, want %v", test.host, test.port, IPs, test.IPs)
+		}
+	}
+}
+
+func TestCrawlerDialer(t *testing.T) {
+	resolves, err := parseResolves([]string{"example.com:443:10.0.0.1,127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Crawler{
+		resolves: resolves,
+	}
+
+	dialed := []string{}
+
+	dial := c.dialer(func(_ context.Context, _, address string) (conn net.Conn, err error) {
+		dialed = append(dialed, address)
+
+		// NOTE: The first override is unreachable, so the next one is dialed.
+		if address == "10.0.0.1:443" {
+			err = errors.New("unreachable")
+
+			return
+		}
+
+		conn, _ = net.Pipe()
+
+		return
+	})
+
+	tests := []struct {
+		address string
+		dialed  []string
+	}{
+		{"example.com:443", []string{"10.0.0.1:443", "127.0.0.1:443"}},
+		{"example.org:443", []string{"example.org:443"}},
+		{"example.com", []string{"example.com"}},
+	}
+
+	for _, test := range tests {
+		dialed = dialed[:0]
+
+		conn, err := dial(context.Background(), "tcp", test.address)
+		if err != nil {
+			t.Errorf("dial(%s) error = %v", test.address, err)
+
+			continue
+		}
+
+		conn.Close()
+
+		if !slices.Equal(dialed, test.dialed) {
+			t.Errorf("dial(%s) dialed %v, want %v", test.address, dialed, test.dialed)
+		}
+	}
+}
+
+func TestNewResolver(t *testing.T) {
+	tests := []struct {
+		servers  []string
+		resolver bool
+		err      error
+	}{
+		{nil, false, nil},
+		{[]string{" ", ""}, false, nil},
+		{[]string{"1.1.1.1", "8.8.8.8:5353", "[2606:4700:4700::1111]"}, true, nil},
+		{[]string{"dns.example.com"}, false, ErrInvalidResolver},
+	}
+
+	for _, test := range tests {
+		resolver, err := newResolver(test.servers)
+
+		if !errors.Is(err, test.err) {
+			t.Errorf("newResolver(%q) error = %v, want %v", test.servers, err, test.err)
+		}
+
+		if (resolver != nil) != test.resolver {
+			t.Errorf("newResolver(%q) resolver = %v, want %t", test.servers, resolver, test.resolver)
+		}
+	}
+}
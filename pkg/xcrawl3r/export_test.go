@@ -0,0 +1,16 @@
+package xcrawl3r
+
+import "net/http"
+
+func NewReplayTransport(name string) (transport http.RoundTripper, err error) {
+	a, err := newArchive(name)
+	if err != nil {
+		return
+	}
+
+	transport = &replayTransport{
+		archive: a,
+	}
+
+	return
+}
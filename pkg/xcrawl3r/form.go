@@ -0,0 +1,145 @@
+package xcrawl3r
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+type Form struct {
+	Method  string      `json:"method"`
+	Enctype string      `json:"enctype"`
+	Fields  []FormField `json:"fields,omitempty"`
+}
+
+func (f *Form) key(action string) (key string) {
+	var b strings.Builder
+
+	b.WriteString(f.Method)
+	b.WriteByte(' ')
+	b.WriteString(action)
+
+	for _, field := range f.Fields {
+		b.WriteByte(' ')
+		b.WriteString(field.Name)
+	}
+
+	key = b.String()
+
+	return
+}
+
+func (f *Form) Query() (query url.Values) {
+	query = url.Values{}
+
+	for _, field := range f.Fields {
+		value, ok := formPlaceholder(field)
+		if !ok {
+			continue
+		}
+
+		query.Add(field.Name, value)
+	}
+
+	return
+}
+
+type FormField struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+}
+
+func parseForm(e *colly.HTMLElement) (form *Form) {
+	form = &Form{
+		Method:  strings.ToUpper(strings.TrimSpace(e.Attr("method"))),
+		Enctype: strings.ToLower(strings.TrimSpace(e.Attr("enctype"))),
+	}
+
+	if form.Method != http.MethodPost {
+		form.Method = http.MethodGet
+	}
+
+	if form.Enctype == "" {
+		form.Enctype = "application/x-www-form-urlencoded"
+	}
+
+	e.DOM.Find("input[name], select[name], textarea[name], button[name]").Each(func(_ int, s *goquery.Selection) {
+		field := FormField{
+			Name: s.AttrOr("name", ""),
+		}
+
+		switch tag := goquery.NodeName(s); tag {
+		case "input":
+			field.Type = strings.ToLower(s.AttrOr("type", "text"))
+			field.Value = s.AttrOr("value", "")
+
+			if (field.Type == "checkbox" || field.Type == "radio") && field.Value == "" {
+				field.Value = "on"
+			}
+		case "select":
+			field.Type = tag
+
+			option := s.Find("option[selected]").First()
+
+			if option.Length() == 0 {
+				option = s.Find("option").First()
+			}
+
+			field.Value = option.AttrOr("value", strings.TrimSpace(option.Text()))
+		case "textarea":
+			field.Type = tag
+			field.Value = s.Text()
+		case "button":
+			field.Type = strings.ToLower(s.AttrOr("type", "submit"))
+			field.Value = s.AttrOr("value", "")
+		}
+
+		form.Fields = append(form.Fields, field)
+	})
+
+	return
+}
+
+func formPlaceholder(field FormField) (value string, ok bool) {
+	switch field.Type {
+	case "submit", "button", "image", "reset", "file":
+		return
+	}
+
+	value, ok = field.Value, true
+
+	if value != "" {
+		return
+	}
+
+	switch field.Type {
+	case "email":
+		value = "xcrawl3r@example.com"
+	case "number", "range":
+		value = "1"
+	case "url":
+		value = "https://example.com"
+	case "tel":
+		value = "5555555555"
+	case "date":
+		value = "2000-01-01"
+	case "datetime-local":
+		value = "2000-01-01T00:00"
+	case "month":
+		value = "2000-01"
+	case "week":
+		value = "2000-W01"
+	case "time":
+		value = "00:00"
+	case "color":
+		value = "#000000"
+	default:
+		value = "xcrawl3r"
+	}
+
+	return
+}
@@ -0,0 +1,139 @@
+package xcrawl3r
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/gocolly/colly/v2"
+)
+
+func TestParseForm(t *testing.T) {
+	tests := []struct {
+		name string
+		HTML string
+		form Form
+	}{
+		{
+			name: "defaults",
+			HTML: `<form action="/search"><input name="q"></form>`,
+			form: Form{
+				Method:  "GET",
+				Enctype: "application/x-www-form-urlencoded",
+				Fields: []FormField{
+					{Name: "q", Type: "text"},
+				},
+			},
+		},
+		{
+			name: "post",
+			HTML: `<form method=" post " enctype="Multipart/Form-Data"><input type="file" name="upload"></form>`,
+			form: Form{
+				Method:  "POST",
+				Enctype: "multipart/form-data",
+				Fields: []FormField{
+					{Name: "upload", Type: "file"},
+				},
+			},
+		},
+		{
+			name: "unknown method",
+			HTML: `<form method="dialog"></form>`,
+			form: Form{
+				Method:  "GET",
+				Enctype: "application/x-www-form-urlencoded",
+			},
+		},
+		{
+			name: "fields",
+			HTML: `<form>
+				<input type="hidden" name="csrf" value="abc">
+				<input type="CHECKBOX" name="remember">
+				<input name="name">
+				<input value="no name">
+				<select name="sort"><option value="asc">Up</option><option value="desc" selected>Down</option></select>
+				<select name="page"><option>1</option><option>2</option></select>
+				<textarea name="comment">hi</textarea>
+				<button name="action" value="save">Save</button>
+			</form>`,
+			form: Form{
+				Method:  "GET",
+				Enctype: "application/x-www-form-urlencoded",
+				Fields: []FormField{
+					{Name: "csrf", Type: "hidden", Value: "abc"},
+					{Name: "remember", Type: "checkbox", Value: "on"},
+					{Name: "name", Type: "text"},
+					{Name: "sort", Type: "select", Value: "desc"},
+					{Name: "page", Type: "select", Value: "1"},
+					{Name: "comment", Type: "textarea", Value: "hi"},
+					{Name: "action", Type: "submit", Value: "save"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			form := parseForm(formElement(t, test.HTML))
+
+			if form.Method != test.form.Method || form.Enctype != test.form.Enctype {
+				t.Errorf("parseForm() = %s %s, want %s %s", form.Method, form.Enctype, test.form.Method, test.form.Enctype)
+			}
+
+			if !slices.Equal(form.Fields, test.form.Fields) {
+				t.Errorf("parseForm() fields = %+v, want %+v", form.Fields, test.form.Fields)
+			}
+		})
+	}
+}
+
+func TestFormQuery(t *testing.T) {
+	form := &Form{
+		Fields: []FormField{
+			{Name: "q", Type: "text"},
+			{Name: "csrf", Type: "hidden", Value: "abc"},
+			{Name: "email", Type: "email"},
+			{Name: "count", Type: "number"},
+			{Name: "tag", Type: "checkbox", Value: "a"},
+			{Name: "tag", Type: "checkbox", Value: "b"},
+			{Name: "upload", Type: "file"},
+			{Name: "go", Type: "submit", Value: "Go"},
+		},
+	}
+
+	query := form.Query().Encode()
+
+	if want := "count=1&csrf=abc&email=xcrawl3r%40example.com&q=xcrawl3r&tag=a&tag=b"; query != want {
+		t.Errorf("Query() = %q, want %q", query, want)
+	}
+}
+
+func TestFormKey(t *testing.T) {
+	a := &Form{Method: "GET", Fields: []FormField{{Name: "q"}}}
+	b := &Form{Method: "GET", Fields: []FormField{{Name: "q", Value: "other"}}}
+	c := &Form{Method: "POST", Fields: []FormField{{Name: "q"}}}
+
+	if a.key("/search") != b.key("/search") {
+		t.Errorf("key() differs for forms differing only in values")
+	}
+
+	if a.key("/search") == c.key("/search") || a.key("/search") == a.key("/find") {
+		t.Errorf("key() equal for forms differing in method or action")
+	}
+}
+
+func formElement(t *testing.T, HTML string) (e *colly.HTMLElement) {
+	t.Helper()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(HTML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	selection := doc.Find("form").First()
+
+	e = colly.NewHTMLElementFromSelectionNode(&colly.Response{}, selection, selection.Nodes[0], 0)
+
+	return
+}
@@ -0,0 +1,635 @@
+package xcrawl3r
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type HAR struct {
+	File      string
+	Bodies    bool
+	BodyLimit int
+}
+
+type harLog struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harCookie    `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []harNameValue `json:"params"`
+	Text     string         `json:"text"`
+	Encoding string         `json:"_encoding,omitempty"`
+	Comment  string         `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+type harWriter struct {
+	mutex   sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	entries int
+	closed  bool
+	err     error
+}
+
+// NOTE: Entries are streamed as they complete, the log being closed by Close.
+func (w *harWriter) Write(entry *harEntry) {
+	w.mutex.Lock()
+
+	defer w.mutex.Unlock()
+
+	if w.closed || w.err != nil {
+		return
+	}
+
+	if w.entries > 0 {
+		if _, w.err = w.file.WriteString(","); w.err != nil {
+			return
+		}
+	}
+
+	if w.err = w.encoder.Encode(entry); w.err != nil {
+		return
+	}
+
+	w.entries++
+}
+
+func (w *harWriter) Close() (err error) {
+	w.mutex.Lock()
+
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	w.closed = true
+
+	err = w.err
+
+	if err == nil {
+		_, err = w.file.WriteString("]}}\n")
+	}
+
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		err = fmt.Errorf("error writing HAR %s: %w", w.file.Name(), err)
+	}
+
+	return
+}
+
+func newHARWriter(name string) (w *harWriter, err error) {
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return
+	}
+
+	var file *os.File
+
+	file, err = os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return
+	}
+
+	creator, err := json.Marshal(harCreator{
+		Name:    "xcrawl3r",
+		Version: harCreatorVersion(),
+	})
+	if err != nil {
+		file.Close()
+
+		return
+	}
+
+	if _, err = fmt.Fprintf(file, `{"log":{"version":"1.2","creator":%s,"entries":[`, creator); err != nil {
+		file.Close()
+
+		return
+	}
+
+	encoder := json.NewEncoder(file)
+
+	encoder.SetEscapeHTML(false)
+
+	w = &harWriter{
+		file:    file,
+		encoder: encoder,
+	}
+
+	return
+}
+
+type harTrace struct {
+	mutex sync.Mutex
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotConn, wroteRequest     time.Time
+	firstByte                 time.Time
+
+	serverIPAddress string
+}
+
+func (t *harTrace) ClientTrace() (trace *httptrace.ClientTrace) {
+	set := func(at *time.Time, first bool) {
+		t.mutex.Lock()
+
+		defer t.mutex.Unlock()
+
+		// NOTE: With parallel dials, starts are taken from the first and ends from the last.
+		if first && !at.IsZero() {
+			return
+		}
+
+		*at = time.Now()
+	}
+
+	trace = &httptrace.ClientTrace{
+		DNSStart:     func(httptrace.DNSStartInfo) { set(&t.dnsStart, true) },
+		DNSDone:      func(httptrace.DNSDoneInfo) { set(&t.dnsDone, false) },
+		ConnectStart: func(_, _ string) { set(&t.connectStart, true) },
+		ConnectDone:  func(_, _ string, _ error) { set(&t.connectDone, false) },
+
+		TLSHandshakeStart: func() { set(&t.tlsStart, true) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { set(&t.tlsDone, false) },
+
+		GotConn: func(info httptrace.GotConnInfo) {
+			set(&t.gotConn, false)
+
+			t.mutex.Lock()
+
+			defer t.mutex.Unlock()
+
+			if info.Conn != nil {
+				if host, _, err := net.SplitHostPort(info.Conn.RemoteAddr().String()); err == nil {
+					t.serverIPAddress = host
+				}
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { set(&t.wroteRequest, false) },
+		GotFirstResponseByte: func() { set(&t.firstByte, false) },
+	}
+
+	return
+}
+
+// NOTE: Phases that did not happen, such as DNS and connecting on reused connections, are -1,
+// and the connect phase includes the TLS handshake, as HAR 1.2 specifies.
+func (t *harTrace) Timings(start, end time.Time) (timings harTimings) {
+	t.mutex.Lock()
+
+	defer t.mutex.Unlock()
+
+	connectDone := t.connectDone
+
+	if !t.tlsDone.IsZero() {
+		connectDone = t.tlsDone
+	}
+
+	timings = harTimings{
+		DNS:     harSpan(t.dnsStart, t.dnsDone),
+		Connect: harSpan(t.connectStart, connectDone),
+		SSL:     harSpan(t.tlsStart, t.tlsDone),
+		Send:    max(harSpan(t.gotConn, t.wroteRequest), 0),
+		Wait:    max(harSpan(t.wroteRequest, t.firstByte), 0),
+		Receive: max(harSpan(t.firstByte, end), 0),
+	}
+
+	timings.Blocked = harSpan(start, t.gotConn)
+
+	if timings.Blocked >= 0 {
+		timings.Blocked = max(math.Round((timings.Blocked-max(timings.DNS, 0)-max(timings.Connect, 0))*1000)/1000, 0)
+	}
+
+	return
+}
+
+type harTransport struct {
+	transport http.RoundTripper
+	writer    *harWriter
+	bodies    bool
+	limit     int
+}
+
+// NOTE: Recorded at the transport, so that entries carry the headers actually sent, redirects
+// and retries are recorded as their own entries, and timings exclude waits on limits.
+func (t *harTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	start := time.Now()
+
+	trace := &harTrace{}
+
+	entry := &harEntry{
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Request:         t.request(req),
+	}
+
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.ClientTrace()))
+
+	res, err = t.transport.RoundTrip(req)
+	if err != nil {
+		end := time.Now()
+
+		entry.Response = harResponse{
+			Cookies:     []harCookie{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		}
+		entry.Error = err.Error()
+		entry.Timings = trace.Timings(start, end)
+		entry.Time = harDuration(end.Sub(start))
+		entry.ServerIPAddress = trace.serverIPAddress
+
+		t.writer.Write(entry)
+
+		return
+	}
+
+	entry.Response = harResponse{
+		Status:      res.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(res.Status, fmt.Sprint(res.StatusCode))),
+		HTTPVersion: res.Proto,
+		Cookies:     harCookies(res.Cookies()),
+		Headers:     harHeaders(res.Header),
+		Content: harContent{
+			MimeType: res.Header.Get("Content-Type"),
+		},
+		RedirectURL: res.Header.Get("Location"),
+		HeadersSize: -1,
+	}
+
+	res.Body = &harBody{
+		ReadCloser: res.Body,
+		transport:  t,
+		entry:      entry,
+		trace:      trace,
+		start:      start,
+		compressed: res.Uncompressed,
+	}
+
+	return
+}
+
+func (t *harTransport) request(req *http.Request) (request harRequest) {
+	headers := req.Header.Clone()
+
+	if req.Host != "" {
+		headers.Set("Host", req.Host)
+	} else {
+		headers.Set("Host", req.URL.Host)
+	}
+
+	request = harRequest{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		HTTPVersion: req.Proto,
+		Cookies:     harCookies(req.Cookies()),
+		Headers:     harHeaders(headers),
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+
+	if request.HTTPVersion == "" {
+		request.HTTPVersion = "HTTP/1.1"
+	}
+
+	request.QueryString = harParams(req.URL.RawQuery)
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	request.PostData = &harPostData{
+		MimeType: req.Header.Get("Content-Type"),
+		Params:   []harNameValue{},
+	}
+
+	// NOTE: Bodies are read from a fresh copy, so requests that cannot provide one are recorded without.
+	if !t.bodies || req.GetBody == nil {
+		return
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return
+	}
+
+	defer body.Close()
+
+	var buffer bytes.Buffer
+
+	size, _ := io.Copy(&buffer, t.limited(body))
+
+	if t.limit > 0 && size > int64(t.limit) {
+		buffer.Truncate(t.limit)
+	}
+
+	request.PostData.Text, request.PostData.Encoding = harText(buffer.Bytes())
+
+	if strings.HasPrefix(request.PostData.MimeType, "application/x-www-form-urlencoded") {
+		request.PostData.Params = harParams(request.PostData.Text)
+	}
+
+	if t.limit > 0 && size > int64(t.limit) {
+		request.PostData.Comment = fmt.Sprintf("truncated to %d bytes", t.limit)
+	}
+
+	return
+}
+
+func (t *harTransport) limited(r io.Reader) (limited io.Reader) {
+	limited = r
+
+	// NOTE: One byte over the limit is read, to tell truncated bodies apart.
+	if t.limit > 0 {
+		limited = io.LimitReader(r, int64(t.limit)+1)
+	}
+
+	return
+}
+
+type harBody struct {
+	io.ReadCloser
+
+	transport  *harTransport
+	entry      *harEntry
+	trace      *harTrace
+	start      time.Time
+	compressed bool
+
+	buffer bytes.Buffer
+	size   int64
+	once   sync.Once
+}
+
+func (b *harBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+
+	b.size += int64(n)
+
+	if b.transport.bodies {
+		remaining := n
+
+		if b.transport.limit > 0 {
+			remaining = min(remaining, b.transport.limit-b.buffer.Len())
+		}
+
+		if remaining > 0 {
+			b.buffer.Write(p[:remaining])
+		}
+	}
+
+	return
+}
+
+func (b *harBody) Close() (err error) {
+	err = b.ReadCloser.Close()
+
+	b.once.Do(func() {
+		end := time.Now()
+
+		b.entry.Response.Content.Size = b.size
+		b.entry.Response.BodySize = b.size
+
+		// NOTE: Bodies decompressed by the transport were transferred in fewer, unknown, bytes.
+		if b.compressed {
+			b.entry.Response.BodySize = -1
+		}
+
+		if b.transport.bodies {
+			b.entry.Response.Content.Text, b.entry.Response.Content.Encoding = harText(b.buffer.Bytes())
+
+			if int64(b.buffer.Len()) < b.size {
+				b.entry.Response.Content.Comment = fmt.Sprintf("truncated to %d bytes", b.buffer.Len())
+			}
+		}
+
+		b.entry.Timings = b.trace.Timings(b.start, end)
+		b.entry.Time = harDuration(end.Sub(b.start))
+		b.entry.ServerIPAddress = b.trace.serverIPAddress
+
+		b.transport.writer.Write(b.entry)
+	})
+
+	return
+}
+
+func harCookies(cookies []*http.Cookie) (entries []harCookie) {
+	entries = []harCookie{}
+
+	for _, cookie := range cookies {
+		entry := harCookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			HTTPOnly: cookie.HttpOnly,
+			Secure:   cookie.Secure,
+		}
+
+		if !cookie.Expires.IsZero() {
+			entry.Expires = cookie.Expires.UTC().Format(time.RFC3339)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return
+}
+
+func harParams(raw string) (entries []harNameValue) {
+	entries = []harNameValue{}
+
+	for pair := range strings.SplitSeq(raw, "&") {
+		if pair == "" {
+			continue
+		}
+
+		name, value, _ := strings.Cut(pair, "=")
+
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+
+		entries = append(entries, harNameValue{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	return
+}
+
+func harHeaders(headers http.Header) (entries []harNameValue) {
+	entries = []harNameValue{}
+
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		for _, value := range headers[name] {
+			entries = append(entries, harNameValue{
+				Name:  name,
+				Value: value,
+			})
+		}
+	}
+
+	return
+}
+
+func harText(data []byte) (text, encoding string) {
+	if utf8.Valid(data) {
+		text = string(data)
+
+		return
+	}
+
+	text, encoding = base64.StdEncoding.EncodeToString(data), "base64"
+
+	return
+}
+
+func harSpan(from, to time.Time) (span float64) {
+	if from.IsZero() || to.IsZero() || to.Before(from) {
+		span = -1
+
+		return
+	}
+
+	span = harDuration(to.Sub(from))
+
+	return
+}
+
+func harDuration(d time.Duration) (ms float64) {
+	ms = math.Round(float64(d)/float64(time.Microsecond)) / 1000
+
+	return
+}
+
+func harCreatorVersion() (v string) {
+	v = "(devel)"
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	if info.Main.Path == modulePath {
+		v = info.Main.Version
+
+		return
+	}
+
+	for _, dependency := range info.Deps {
+		if dependency.Path == modulePath {
+			v = dependency.Version
+
+			break
+		}
+	}
+
+	return
+}
+
+const modulePath = "github.com/hueristiq/xcrawl3r"
@@ -0,0 +1,213 @@
+package xcrawl3r
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHARWriter(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "logs", "crawl.har")
+
+	w, err := newHARWriter(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Write(&harEntry{Request: harRequest{URL: "https://example.com/a"}})
+	w.Write(&harEntry{Request: harRequest{URL: "https://example.com/b"}})
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: Entries written after Close are dropped, and closing again is a no-op.
+	w.Write(&harEntry{Request: harRequest{URL: "https://example.com/c"}})
+
+	if err := w.Close(); err != nil {
+		t.Errorf("Close() again error = %v", err)
+	}
+
+	info, err := os.Stat(filepath.Dir(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mode := info.Mode().Perm(); mode&0o027 != 0 {
+		t.Errorf("newHARWriter() directory mode = %v, want at most 0750", mode)
+	}
+
+	log := readHAR(t, name)
+
+	if len(log.Log.Entries) != 2 || log.Log.Entries[0].Request.URL != "https://example.com/a" || log.Log.Entries[1].Request.URL != "https://example.com/b" {
+		t.Errorf("newHARWriter() entries = %+v, want a & b", log.Log.Entries)
+	}
+}
+
+func TestHARTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+
+		w.Header().Set("Content-Type", "text/plain")
+
+		io.WriteString(w, "0123456789")
+	}))
+
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		bodies  bool
+		limit   int
+		text    string
+		post    string
+		comment string
+	}{
+		{"no bodies", false, 0, "", "", ""},
+		{"bodies", true, 0, "0123456789", "q=1&page=2", ""},
+		{"limited bodies", true, 4, "0123", "q=1&", "truncated to 4 bytes"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "crawl.har")
+
+			writer, err := newHARWriter(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := &http.Client{
+				Transport: &harTransport{
+					transport: http.DefaultTransport,
+					writer:    writer,
+					bodies:    test.bodies,
+					limit:     test.limit,
+				},
+			}
+
+			res, err := client.Post(server.URL+"/search?x=1", "application/x-www-form-urlencoded", strings.NewReader("q=1&page=2"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, _ := io.ReadAll(res.Body)
+
+			res.Body.Close()
+
+			// NOTE: Limits apply to the recording, not to the body read by the crawler.
+			if string(body) != "0123456789" {
+				t.Errorf("body = %q, want the full body", body)
+			}
+
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			log := readHAR(t, name)
+
+			if len(log.Log.Entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(log.Log.Entries))
+			}
+
+			entry := log.Log.Entries[0]
+
+			if entry.Request.Method != http.MethodPost || len(entry.Request.QueryString) != 1 || entry.Request.PostData == nil {
+				t.Errorf("request = %+v, want a POST with a query string and post data", entry.Request)
+			}
+
+			if entry.Request.PostData != nil && (entry.Request.PostData.Text != test.post || entry.Request.PostData.Comment != test.comment) {
+				t.Errorf("post data = %q (%q), want %q (%q)", entry.Request.PostData.Text, entry.Request.PostData.Comment, test.post, test.comment)
+			}
+
+			response := entry.Response
+
+			if response.Status != http.StatusOK || response.Content.Size != 10 || response.Content.MimeType != "text/plain" {
+				t.Errorf("response = %d, %d bytes of %s, want 200, 10 bytes of text/plain", response.Status, response.Content.Size, response.Content.MimeType)
+			}
+
+			if response.Content.Text != test.text || response.Content.Comment != test.comment {
+				t.Errorf("content = %q (%q), want %q (%q)", response.Content.Text, response.Content.Comment, test.text, test.comment)
+			}
+
+			if len(response.Cookies) != 1 || response.Cookies[0].Name != "session" {
+				t.Errorf("cookies = %+v, want session", response.Cookies)
+			}
+		})
+	}
+}
+
+func TestHARTransportError(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "crawl.har")
+
+	writer, err := newHARWriter(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	transport := &harTransport{
+		transport: roundTripperFunc(func(req *http.Request) (res *http.Response, err error) {
+			err = errors.New("connection refused")
+
+			return
+		}),
+		writer: writer,
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Fatal("RoundTrip() error = nil, want error")
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	log := readHAR(t, name)
+
+	if len(log.Log.Entries) != 1 || log.Log.Entries[0].Error != "connection refused" || log.Log.Entries[0].Response.BodySize != -1 {
+		t.Errorf("entries = %+v, want the failed request", log.Log.Entries)
+	}
+}
+
+func TestHARText(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		text     string
+		encoding string
+	}{
+		{[]byte("plain"), "plain", ""},
+		{[]byte{0xff, 0xfe}, "//4=", "base64"},
+	}
+
+	for _, test := range tests {
+		if text, encoding := harText(test.data); text != test.text || encoding != test.encoding {
+			t.Errorf("harText(%q) = %q, %q, want %q, %q", test.data, text, encoding, test.text, test.encoding)
+		}
+	}
+}
+
+func readHAR(t *testing.T, name string) (log harLog) {
+	t.Helper()
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid HAR %s: %v", data, err)
+	}
+
+	return
+}
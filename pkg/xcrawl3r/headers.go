@@ -0,0 +1,109 @@
+package xcrawl3r
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type hostHeader struct {
+	rule   hostRule
+	header string
+	value  string
+}
+
+type headerTransport struct {
+	transport http.RoundTripper
+	headers   []hostHeader
+}
+
+// NOTE: Host headers are set per hop, so redirects to other hosts never carry them.
+func (t *headerTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	host, hostErr := normalizeHost(req.URL.Hostname())
+
+	cloned := false
+
+	for _, h := range t.headers {
+		if hostErr != nil || !h.rule.Match(host) {
+			continue
+		}
+
+		if !cloned {
+			req = req.Clone(req.Context())
+
+			cloned = true
+		}
+
+		req.Header.Set(h.header, h.value)
+	}
+
+	res, err = t.transport.RoundTrip(req)
+
+	return
+}
+
+// NOTE: Entries in `host=Header: value` format are scoped to hosts matching the pattern.
+func parseHostHeader(entry string) (host, header, value string, ok bool) {
+	host, rest, found := strings.Cut(entry, "=")
+	if !found || host == "" || strings.ContainsAny(host, " \t") {
+		return
+	}
+
+	if _, err := parseHostRule(strings.ToLower(host)); err != nil {
+		return
+	}
+
+	header, value, ok = parseHeader(rest)
+
+	return
+}
+
+func newHostHeaders(entries []string, hosts map[string][]string) (headers []hostHeader, err error) {
+	add := func(host, header, value string) (err error) {
+		var rule hostRule
+
+		rule, err = parseHostRule(strings.ToLower(strings.TrimSpace(host)))
+		if err != nil {
+			err = fmt.Errorf("error parsing header host %s: %w", host, err)
+
+			return
+		}
+
+		headers = append(headers, hostHeader{
+			rule:   rule,
+			header: header,
+			value:  value,
+		})
+
+		return
+	}
+
+	for _, entry := range entries {
+		host, header, value, ok := parseHostHeader(entry)
+		if !ok {
+			continue
+		}
+
+		if err = add(host, header, value); err != nil {
+			return
+		}
+	}
+
+	// NOTE: Sorted, so that overlapping patterns apply in a stable order.
+	for _, host := range slices.Sorted(maps.Keys(hosts)) {
+		for _, entry := range hosts[host] {
+			header, value, ok := parseHeader(entry)
+			if !ok {
+				continue
+			}
+
+			if err = add(host, header, value); err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
@@ -0,0 +1,140 @@
+package xcrawl3r
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseHostHeader(t *testing.T) {
+	tests := []struct {
+		entry  string
+		host   string
+		header string
+		value  string
+		ok     bool
+	}{
+		{"api.example.com=X-Token: abc", "api.example.com", "X-Token", "abc", true},
+		{"*.example.com=Authorization: Bearer a=b", "*.example.com", "Authorization", "Bearer a=b", true},
+		{"10.0.0.0/8=X-Internal:1", "10.0.0.0/8", "X-Internal", "1", true},
+		{"[::1]=X-Token: abc", "[::1]", "X-Token", "abc", true},
+		{"EXAMPLE.com=X-Token: abc", "EXAMPLE.com", "X-Token", "abc", true},
+		// NOTE: Unscoped headers, whose values may contain `=`, are not host headers.
+		{"Authorization: Bearer a=b", "", "", "", false},
+		{"X-Token:a=b", "", "", "", false},
+		{"=X-Token: abc", "", "", "", false},
+		{"example.com=no header", "", "", "", false},
+		{"example.com:8080=X-Token: abc", "", "", "", false},
+		{"a..b=X-Token: abc", "", "", "", false},
+	}
+
+	for _, test := range tests {
+		host, header, value, ok := parseHostHeader(test.entry)
+
+		if ok != test.ok {
+			t.Errorf("parseHostHeader(%q) ok = %t, want %t", test.entry, ok, test.ok)
+
+			continue
+		}
+
+		if ok && (host != test.host || header != test.header || value != test.value) {
+			t.Errorf("parseHostHeader(%q) = %q, %q, %q, want %q, %q, %q", test.entry, host, header, value, test.host, test.header, test.value)
+		}
+	}
+}
+
+func TestNewHostHeaders(t *testing.T) {
+	headers, err := newHostHeaders(
+		[]string{"api.example.com=X-Token: abc", "X-Unscoped: 1"},
+		map[string][]string{
+			"*.example.com": {"X-Wildcard: w", "invalid"},
+			"10.0.0.0/8":    {"X-Internal: i"},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"X-Token", "X-Wildcard", "X-Internal"}
+
+	if len(headers) != len(want) {
+		t.Fatalf("newHostHeaders() = %d headers, want %d", len(headers), len(want))
+	}
+
+	for i, header := range headers {
+		if header.header != want[i] {
+			t.Errorf("newHostHeaders()[%d] = %s, want %s", i, header.header, want[i])
+		}
+	}
+
+	if _, err := newHostHeaders(nil, map[string][]string{"a..b": {"X-Token: abc"}}); err == nil {
+		t.Errorf("newHostHeaders() with an invalid host error = nil, want error")
+	}
+}
+
+func TestHeaderTransport(t *testing.T) {
+	headers, err := newHostHeaders(
+		[]string{"api.example.com=X-Token: abc", "*.example.com=X-Wildcard: w", "10.0.0.0/8=X-Internal: i"},
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		URL     string
+		headers map[string]string
+	}{
+		{"https://api.example.com/", map[string]string{"X-Token": "abc", "X-Wildcard": "w"}},
+		{"https://API.Example.com:8443/", map[string]string{"X-Token": "abc", "X-Wildcard": "w"}},
+		{"https://www.example.com/", map[string]string{"X-Wildcard": "w"}},
+		{"http://10.1.2.3/", map[string]string{"X-Internal": "i"}},
+		{"https://example.org/", map[string]string{}},
+	}
+
+	for _, test := range tests {
+		var sent http.Header
+
+		transport := &headerTransport{
+			transport: roundTripperFunc(func(req *http.Request) (res *http.Response, err error) {
+				sent = req.Header
+
+				res = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       http.NoBody,
+				}
+
+				return
+			}),
+			headers: headers,
+		}
+
+		req, err := http.NewRequest(http.MethodGet, test.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := transport.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(sent) != len(test.headers) {
+			t.Errorf("RoundTrip(%s) sent %v, want %v", test.URL, sent, test.headers)
+		}
+
+		for header, value := range test.headers {
+			if sent.Get(header) != value {
+				t.Errorf("RoundTrip(%s) %s = %q, want %q", test.URL, header, sent.Get(header), value)
+			}
+		}
+
+		if len(req.Header) != 0 {
+			t.Errorf("RoundTrip(%s) modified the caller's request headers: %v", test.URL, req.Header)
+		}
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (res *http.Response, err error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	return f(req)
+}
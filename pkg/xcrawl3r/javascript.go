@@ -0,0 +1,155 @@
+package xcrawl3r
+
+import (
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+func extractJavaScriptEndpoints(body string) (endpoints []string) {
+	seen := map[string]struct{}{}
+
+	add := func(endpoint string, loose bool) {
+		endpoint = normalizeJavaScriptEndpoint(endpoint)
+
+		if len(endpoint) < 2 || len(endpoint) > 1024 {
+			return
+		}
+
+		if !loose && !javaScriptEndpointRegex.MatchString(endpoint) {
+			return
+		}
+
+		if strings.ContainsAny(endpoint, " \t\r\n") || !strings.ContainsFunc(endpoint, unicode.IsLetter) || isMIMEType(endpoint) {
+			return
+		}
+
+		if _, ok := seen[endpoint]; ok {
+			return
+		}
+
+		seen[endpoint] = struct{}{}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	for _, regex := range []*regexp.Regexp{javaScriptCallRegex, javaScriptXHRRegex} {
+		for _, match := range regex.FindAllStringSubmatch(body, -1) {
+			add(match[1], true)
+		}
+	}
+
+	for _, match := range javaScriptStringRegex.FindAllStringSubmatch(body, -1) {
+		for _, literal := range match[1:] {
+			if literal != "" {
+				add(literal, false)
+			}
+		}
+	}
+
+	return
+}
+
+func normalizeJavaScriptEndpoint(literal string) (endpoint string) {
+	endpoint = javaScriptUnescaper.Replace(literal)
+
+	// NOTE: A leading template expression is the base URL, e.g. `${base}/orders`.
+	if loc := javaScriptTemplateExpressionRegex.FindStringIndex(endpoint); loc != nil {
+		if loc[0] == 0 {
+			endpoint = endpoint[loc[1]:]
+		}
+
+		if i := strings.Index(endpoint, "${"); i >= 0 {
+			endpoint = endpoint[:i]
+		}
+	}
+
+	endpoint = strings.TrimSpace(endpoint)
+
+	return
+}
+
+func resolveJavaScriptEndpoint(script *url.URL, endpoint string) (URL string) {
+	switch {
+	case strings.HasPrefix(endpoint, "//"):
+		URL = script.Scheme + ":" + endpoint
+	case strings.Contains(endpoint, "://"):
+		URL = endpoint
+	default:
+		if !strings.HasPrefix(endpoint, "/") {
+			endpoint = "/" + endpoint
+		}
+
+		ref, err := url.Parse(endpoint)
+		if err != nil {
+			return
+		}
+
+		origin := &url.URL{
+			Scheme: script.Scheme,
+			Host:   script.Host,
+		}
+
+		URL = origin.ResolveReference(ref).String()
+	}
+
+	return
+}
+
+func isJavaScript(URL *url.URL, contentType string) (is bool) {
+	switch path.Ext(URL.Path) {
+	case ".js", ".mjs", ".json":
+		is = true
+
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	is = strings.Contains(mediaType, "javascript") || strings.HasSuffix(mediaType, "json")
+
+	return
+}
+
+func isMIMEType(endpoint string) (is bool) {
+	for _, prefix := range []string{"application/", "audio/", "font/", "image/", "multipart/", "text/", "video/"} {
+		if strings.HasPrefix(endpoint, prefix) {
+			is = true
+
+			return
+		}
+	}
+
+	return
+}
+
+var (
+	javaScriptStringRegex = regexp.MustCompile(`"((?:[^"\\\n]|\\.)*)"|'((?:[^'\\\n]|\\.)*)'|` + "`" + `((?:[^` + "`" + `\\]|\\.)*)` + "`")
+	javaScriptCallRegex   = regexp.MustCompile(`(?:\bfetch|\baxios(?:\.(?:get|post|put|patch|delete|head|options|request))?|\$\.(?:ajax|get|post|getJSON))\s*\(\s*["'` + "`" + `]([^"'` + "`" + `\s]+)["'` + "`" + `]`)
+	javaScriptXHRRegex    = regexp.MustCompile(`\.open\s*\(\s*["'][A-Za-z]+["']\s*,\s*["'` + "`" + `]([^"'` + "`" + `\s]+)["'` + "`" + `]`)
+
+	javaScriptEndpointRegex = regexp.MustCompile(`^(?:` +
+		// full URLs
+		`(?:[a-zA-Z]{1,10}://|//)[^"'/\s]+\.[a-zA-Z]{2,}[^"'\s]*` +
+		// paths starting with /, ../ or ./
+		`|(?:/|\.\./|\./)[^"'><,;|\s*()%$^/\\\[\]][^"'><,;|()\s]+` +
+		// relative paths with an extension
+		`|[a-zA-Z0-9_\-/]+/[a-zA-Z0-9_\-/.]+\.(?:[a-zA-Z]{1,4}|action)(?:[?#][^"'\s]*)?` +
+		// REST API paths
+		`|[a-zA-Z0-9_\-/]+/[a-zA-Z0-9_\-/]{3,}(?:[?#][^"'\s]*)?` +
+		// file names
+		`|[a-zA-Z0-9_\-]+\.(?:php|asp|aspx|jsp|json|action|html|js|txt|xml)(?:[?#][^"'\s]*)?` +
+		`)$`)
+
+	javaScriptTemplateExpressionRegex = regexp.MustCompile(`\$\{[^}]*\}`)
+
+	javaScriptUnescaper = strings.NewReplacer(
+		`\/`, "/",
+		`\u002f`, "/",
+		`\u002F`, "/",
+		`\u0026`, "&",
+	)
+)
@@ -0,0 +1,117 @@
+package xcrawl3r
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func TestExtractJavaScriptEndpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		endpoints []string
+	}{
+		{
+			name:      "calls",
+			body:      `fetch("/api/orders"); axios.get('/api/users'); $.getJSON("data")`,
+			endpoints: []string{"/api/orders", "/api/users", "data"},
+		},
+		{
+			name:      "xhr",
+			body:      `xhr.open("GET", "/legacy/endpoint.php?id=1")`,
+			endpoints: []string{"/legacy/endpoint.php?id=1"},
+		},
+		{
+			name:      "template",
+			body:      "const u = `${base}/api/v2/items/${id}`;",
+			endpoints: []string{"/api/v2/items/"},
+		},
+		{
+			name:      "relative",
+			body:      `var a = "api/v1/products", b = "./rel/path.js", c = "../up.json", d = "config.json"`,
+			endpoints: []string{"api/v1/products", "./rel/path.js", "../up.json", "config.json"},
+		},
+		{
+			name:      "escaped",
+			body:      `var e = "https:\/\/cdn.example.com\/x.js", f = "/api/escaped"`,
+			endpoints: []string{"https://cdn.example.com/x.js", "/api/escaped"},
+		},
+		{
+			name:      "protocol relative",
+			body:      `"//static.example.com/img.png"`,
+			endpoints: []string{"//static.example.com/img.png"},
+		},
+		{
+			name:      "duplicates",
+			body:      `var l = "/api/orders"; fetch("/api/orders")`,
+			endpoints: []string{"/api/orders"},
+		},
+		{
+			name: "noise",
+			body: `var a = "text/html", b = "application/json", c = "12/31/2020", d = "hello world", e = "/", f = "use strict"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			endpoints := extractJavaScriptEndpoints(test.body)
+
+			if !slices.Equal(endpoints, test.endpoints) {
+				t.Errorf("extractJavaScriptEndpoints() = %q, want %q", endpoints, test.endpoints)
+			}
+		})
+	}
+}
+
+func TestResolveJavaScriptEndpoint(t *testing.T) {
+	script, err := url.Parse("https://example.com/static/app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		endpoint string
+		URL      string
+	}{
+		{"/api", "https://example.com/api"},
+		{"api/v1", "https://example.com/api/v1"},
+		{"./rel.js", "https://example.com/rel.js"},
+		{"../up.json", "https://example.com/up.json"},
+		{"a?b=1#c", "https://example.com/a?b=1#c"},
+		{"//cdn.example.com/a", "https://cdn.example.com/a"},
+		{"https://other.com/x", "https://other.com/x"},
+	}
+
+	for _, test := range tests {
+		if URL := resolveJavaScriptEndpoint(script, test.endpoint); URL != test.URL {
+			t.Errorf("resolveJavaScriptEndpoint(%q) = %q, want %q", test.endpoint, URL, test.URL)
+		}
+	}
+}
+
+func TestIsJavaScript(t *testing.T) {
+	tests := []struct {
+		path        string
+		contentType string
+		is          bool
+	}{
+		{"/app.js", "", true},
+		{"/app.mjs", "", true},
+		{"/data.json", "", true},
+		{"/app", "application/javascript; charset=utf-8", true},
+		{"/app", "application/ld+json", true},
+		{"/app", "text/html", false},
+		{"/app.css", "text/css", false},
+	}
+
+	for _, test := range tests {
+		u := &url.URL{
+			Path: test.path,
+		}
+
+		if is := isJavaScript(u, test.contentType); is != test.is {
+			t.Errorf("isJavaScript(%q, %q) = %t, want %t", test.path, test.contentType, is, test.is)
+		}
+	}
+}
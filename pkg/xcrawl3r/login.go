@@ -0,0 +1,424 @@
+package xcrawl3r
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+type Login struct {
+	Steps         []LoginStep
+	Headers       []string
+	LogoutURL     string
+	LogoutMarkers []string
+}
+
+type LoginStep struct {
+	Method  string
+	URL     string
+	Headers []string
+	Body    string
+	Extract []LoginExtract
+}
+
+type LoginExtract struct {
+	Name   string
+	Regex  string
+	JSON   string
+	Header string
+}
+
+type session struct {
+	login   *Login
+	target  *url.URL
+	host    string
+	logout  *url.URL
+	client  *http.Client
+	headers http.Header
+
+	mutex      sync.RWMutex
+	generation int
+	injected   http.Header
+}
+
+// NOTE: Authenticate is a no-op if another request already re-authenticated since generation.
+func (s *session) Authenticate(ctx context.Context, generation int) (err error) {
+	s.mutex.Lock()
+
+	defer s.mutex.Unlock()
+
+	if s.generation != generation {
+		return
+	}
+
+	values := map[string]string{}
+
+	for i, step := range s.login.Steps {
+		if err = s.step(ctx, step, values); err != nil {
+			err = fmt.Errorf("error running login step %d: %w", i+1, err)
+
+			return
+		}
+	}
+
+	injected := http.Header{}
+
+	for _, entry := range s.login.Headers {
+		if header, value, ok := parseHeader(expand(entry, values, nil)); ok {
+			injected.Set(header, value)
+		}
+	}
+
+	s.injected = injected
+	s.generation++
+
+	return
+}
+
+func (s *session) step(ctx context.Context, step LoginStep, values map[string]string) (err error) {
+	var URL *url.URL
+
+	URL, err = s.target.Parse(expandURL(step.URL, values))
+	if err != nil {
+		return
+	}
+
+	headers := s.headers.Clone()
+
+	if step.Body != "" {
+		headers.Set("Content-Type", formContentType)
+	}
+
+	for _, entry := range step.Headers {
+		if header, value, ok := parseHeader(expand(entry, values, nil)); ok {
+			headers.Set(header, value)
+		}
+	}
+
+	var body io.Reader
+
+	if step.Body != "" {
+		var escape func(string) string
+
+		// NOTE: Values are encoded into form bodies, and left as they are in others, such as JSON.
+		if mediaType, _, _ := mime.ParseMediaType(headers.Get("Content-Type")); mediaType == formContentType {
+			escape = url.QueryEscape
+		}
+
+		body = strings.NewReader(expand(step.Body, values, escape))
+	}
+
+	method := strings.ToUpper(step.Method)
+
+	if method == "" {
+		method = http.MethodGet
+
+		if body != nil {
+			method = http.MethodPost
+		}
+	}
+
+	var req *http.Request
+
+	req, err = http.NewRequestWithContext(ctx, method, URL.String(), body)
+	if err != nil {
+		return
+	}
+
+	req.Header = headers
+
+	var res *http.Response
+
+	res, err = s.client.Do(req)
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	var data []byte
+
+	data, err = io.ReadAll(io.LimitReader(res.Body, maxLoginBodySize))
+	if err != nil {
+		return
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf("error requesting %s (status %d): %w", URL, res.StatusCode, ErrLoginFailed)
+
+		return
+	}
+
+	for _, extract := range step.Extract {
+		value, ok := "", false
+
+		switch {
+		case extract.Header != "":
+			value = res.Header.Get(extract.Header)
+			ok = value != ""
+		case extract.JSON != "":
+			value, ok = jsonValue(data, extract.JSON)
+		case extract.Regex != "":
+			var regex *regexp.Regexp
+
+			regex, err = regexp.Compile(extract.Regex)
+			if err != nil {
+				return
+			}
+
+			if match := regex.FindSubmatch(data); match != nil {
+				value, ok = string(match[len(match)-1]), true
+			}
+		}
+
+		if !ok {
+			err = fmt.Errorf("error extracting %s from %s: %w", extract.Name, URL, ErrLoginFailed)
+
+			return
+		}
+
+		values[extract.Name] = value
+	}
+
+	return
+}
+
+func (s *session) Generation() (generation int) {
+	s.mutex.RLock()
+
+	defer s.mutex.RUnlock()
+
+	generation = s.generation
+
+	return
+}
+
+// NOTE: Headers, such as a bearer token, belong to the target's host and are not sent to others,
+// such as third-party or CDN hosts.
+func (s *session) Headers(u *url.URL) (headers http.Header) {
+	host, err := normalizeHost(u.Hostname())
+	if err != nil || host != s.host {
+		return
+	}
+
+	s.mutex.RLock()
+
+	defer s.mutex.RUnlock()
+
+	headers = s.injected
+
+	return
+}
+
+// NOTE: colly marks redirect targets as visited before our redirect handler runs, so later redirects
+// to the logout URL surface as already visited errors.
+func (s *session) LoggedOut(err error, body []byte) (logout bool) {
+	var alreadyVisitedError *colly.AlreadyVisitedError
+
+	switch {
+	case errors.Is(err, ErrLoggedOut):
+		logout = true
+	case errors.As(err, &alreadyVisitedError):
+		logout = s.IsLogoutURL(alreadyVisitedError.Destination)
+	default:
+		logout = s.HasLogoutMarker(body)
+	}
+
+	return
+}
+
+func (s *session) IsLogoutURL(u *url.URL) (logout bool) {
+	if s.logout == nil {
+		return
+	}
+
+	logout = strings.EqualFold(u.Host, s.logout.Host) && strings.HasPrefix(u.Path, s.logout.Path)
+
+	return
+}
+
+func (s *session) HasLogoutMarker(body []byte) (logout bool) {
+	for _, marker := range s.login.LogoutMarkers {
+		if marker != "" && bytes.Contains(body, []byte(marker)) {
+			logout = true
+
+			return
+		}
+	}
+
+	return
+}
+
+type sessionTransport struct {
+	transport http.RoundTripper
+	session   *session
+}
+
+// NOTE: Headers are injected as the request is sent, as requests queued before a re-authentication
+// would otherwise carry stale ones.
+func (t *sessionTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	headers := t.session.Headers(req.URL)
+
+	if len(headers) > 0 {
+		req = req.Clone(req.Context())
+
+		for header := range headers {
+			req.Header.Set(header, headers.Get(header))
+		}
+	}
+
+	res, err = t.transport.RoundTrip(req)
+
+	return
+}
+
+func (c *Crawler) newSession(target string) (s *session, err error) {
+	s = &session{
+		login:   c.cfg.Login,
+		headers: c.headers,
+	}
+
+	s.client, err = c.client(nil, target)
+	if err != nil {
+		return
+	}
+
+	s.client.Jar = c.jar
+	s.client.Timeout = time.Duration(c.cfg.Timeout) * time.Second
+
+	s.target, err = url.Parse(target)
+	if err != nil {
+		return
+	}
+
+	s.host, err = normalizeHost(s.target.Hostname())
+	if err != nil {
+		return
+	}
+
+	// NOTE: Without an explicit logout URL, a redirect to the first login step's URL means we were logged out.
+	logout := c.cfg.Login.LogoutURL
+
+	if logout == "" && len(c.cfg.Login.Steps) > 0 && !variableRegex.MatchString(c.cfg.Login.Steps[0].URL) {
+		logout = c.cfg.Login.Steps[0].URL
+	}
+
+	if logout != "" {
+		s.logout, err = s.target.Parse(logout)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func expand(text string, values map[string]string, escape func(string) string) (expanded string) {
+	expanded = variableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		name := variableRegex.FindStringSubmatch(match)[1]
+
+		value, ok := values[name]
+		if !ok {
+			return match
+		}
+
+		if escape != nil {
+			value = escape(value)
+		}
+
+		return value
+	})
+
+	return
+}
+
+// NOTE: Values are encoded into the query, and left as they are in the rest, which a value may
+// make up whole, e.g. an extracted redirect URL.
+func expandURL(URL string, values map[string]string) (expanded string) {
+	URL, query, found := strings.Cut(URL, "?")
+
+	expanded = expand(URL, values, nil)
+
+	if found {
+		expanded += "?" + expand(query, values, url.QueryEscape)
+	}
+
+	return
+}
+
+func jsonValue(data []byte, path string) (value string, ok bool) {
+	var v any
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return
+	}
+
+	for key := range strings.SplitSeq(path, ".") {
+		switch t := v.(type) {
+		case map[string]any:
+			if v, ok = t[key]; !ok {
+				return
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(t) {
+				ok = false
+
+				return
+			}
+
+			v = t[i]
+		default:
+			ok = false
+
+			return
+		}
+	}
+
+	switch t := v.(type) {
+	case nil:
+		ok = false
+
+		return
+	case string:
+		value = t
+	default:
+		data, err := json.Marshal(t)
+		if err != nil {
+			ok = false
+
+			return
+		}
+
+		value = string(data)
+	}
+
+	ok = true
+
+	return
+}
+
+const (
+	formContentType  = "application/x-www-form-urlencoded"
+	maxLoginBodySize = 10 << 20
+)
+
+var (
+	variableRegex = regexp.MustCompile(`\{\{\s*([\w-]+)\s*\}\}`)
+
+	ErrLoginFailed = errors.New("login failed")
+	ErrLoggedOut   = errors.New("logged out")
+)
@@ -0,0 +1,55 @@
+package xcrawl3r
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	values := map[string]string{
+		"csrf":  "ab+c/d==",
+		"token": "a b&c",
+		"next":  "https://example.com/app?x=1",
+	}
+
+	tests := []struct {
+		text     string
+		escape   func(string) string
+		expanded string
+	}{
+		{"csrf={{csrf}}&user=a", url.QueryEscape, "csrf=ab%2Bc%2Fd%3D%3D&user=a"},
+		{"Authorization: Bearer {{ token }}", nil, "Authorization: Bearer a b&c"},
+		{`{"csrf":"{{csrf}}"}`, nil, `{"csrf":"ab+c/d=="}`},
+		{"{{missing}}", url.QueryEscape, "{{missing}}"},
+	}
+
+	for _, test := range tests {
+		if expanded := expand(test.text, values, test.escape); expanded != test.expanded {
+			t.Errorf("expand(%q) = %q, want %q", test.text, expanded, test.expanded)
+		}
+	}
+}
+
+func TestExpandURL(t *testing.T) {
+	values := map[string]string{
+		"csrf": "ab+c/d==",
+		"id":   "42",
+		"next": "https://example.com/app?x=1",
+	}
+
+	tests := []struct {
+		URL      string
+		expanded string
+	}{
+		{"/login?csrf={{csrf}}", "/login?csrf=ab%2Bc%2Fd%3D%3D"},
+		{"/users/{{id}}", "/users/42"},
+		{"{{next}}", "https://example.com/app?x=1"},
+		{"/go?next={{next}}", "/go?next=https%3A%2F%2Fexample.com%2Fapp%3Fx%3D1"},
+	}
+
+	for _, test := range tests {
+		if expanded := expandURL(test.URL, values); expanded != test.expanded {
+			t.Errorf("expandURL(%q) = %q, want %q", test.URL, expanded, test.expanded)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package xcrawl3r
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type parameters struct {
+	mutex sync.Mutex
+	seen  map[string]struct{}
+}
+
+func (p *parameters) Add(endpoint, name string) (added bool) {
+	key := endpoint + "\x00" + name
+
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	if _, ok := p.seen[key]; ok {
+		return
+	}
+
+	p.seen[key] = struct{}{}
+
+	added = true
+
+	return
+}
+
+func newParameters() (p *parameters) {
+	p = &parameters{
+		seen: map[string]struct{}{},
+	}
+
+	return
+}
+
+func parameterEndpoint(u *url.URL) (endpoint string) {
+	endpoint = (&url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   u.Path,
+	}).String()
+
+	return
+}
+
+func parameterHost(u *url.URL) (host string) {
+	host = (&url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+	}).String()
+
+	return
+}
+
+func queryParameterNames(u *url.URL) (names []string) {
+	query, _ := url.ParseQuery(u.RawQuery)
+
+	for name := range query {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return
+}
+
+func jsonParameterNames(body []byte) (names []string) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+
+	var data any
+
+	if err := decoder.Decode(&data); err != nil {
+		return
+	}
+
+	seen := map[string]struct{}{}
+
+	var walk func(value any)
+
+	walk = func(value any) {
+		switch v := value.(type) {
+		case map[string]any:
+			for name, child := range v {
+				if _, ok := seen[name]; !ok && name != "" {
+					seen[name] = struct{}{}
+
+					names = append(names, name)
+				}
+
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+
+	walk(data)
+
+	return
+}
+
+func javaScriptParameterNames(body string) (names []string) {
+	seen := map[string]struct{}{}
+
+	for _, match := range javaScriptParameterRegex.FindAllStringSubmatch(body, -1) {
+		name := match[1]
+
+		if _, ok := seen[name]; ok {
+			continue
+		}
+
+		seen[name] = struct{}{}
+
+		names = append(names, name)
+	}
+
+	return
+}
+
+func isJSON(URL *url.URL, contentType string) (is bool) {
+	if path.Ext(URL.Path) == ".json" {
+		is = true
+
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	is = strings.HasSuffix(mediaType, "json")
+
+	return
+}
+
+var javaScriptParameterRegex = regexp.MustCompile(`(?:[Pp]arams|[Qq]uery|[Ff]orm[Dd]ata|[Ff]orm|[Bb]ody)\s*\.\s*(?:append|set)\(\s*["'` + "`" + `]([A-Za-z_$][\w$\-\[\].]{0,63})["'` + "`" + `]\s*,`)
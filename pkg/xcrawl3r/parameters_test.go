@@ -0,0 +1,160 @@
+package xcrawl3r
+
+import (
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestParameterEndpoint(t *testing.T) {
+	tests := []struct {
+		URL      string
+		endpoint string
+		host     string
+	}{
+		{"https://example.com/search?q=1#top", "https://example.com/search", "https://example.com"},
+		{"http://example.com:8080/a/b/?x=1", "http://example.com:8080/a/b/", "http://example.com:8080"},
+		{"https://example.com", "https://example.com", "https://example.com"},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if endpoint := parameterEndpoint(u); endpoint != test.endpoint {
+			t.Errorf("parameterEndpoint(%q) = %q, want %q", test.URL, endpoint, test.endpoint)
+		}
+
+		if host := parameterHost(u); host != test.host {
+			t.Errorf("parameterHost(%q) = %q, want %q", test.URL, host, test.host)
+		}
+	}
+}
+
+func TestQueryParameterNames(t *testing.T) {
+	tests := []struct {
+		URL   string
+		names []string
+	}{
+		{"https://example.com/?b=2&a=1&a=3", []string{"a", "b"}},
+		{"https://example.com/?flag&empty=&%20=x", []string{"empty", "flag"}},
+		{"https://example.com/", nil},
+	}
+
+	for _, test := range tests {
+		u, err := url.Parse(test.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := queryParameterNames(u)
+
+		slices.Sort(names)
+
+		if !slices.Equal(names, test.names) {
+			t.Errorf("queryParameterNames(%q) = %q, want %q", test.URL, names, test.names)
+		}
+	}
+}
+
+func TestJSONParameterNames(t *testing.T) {
+	tests := []struct {
+		body  string
+		names []string
+	}{
+		{`{"user":{"id":1,"name":"a"},"items":[{"sku":"x"},{"sku":"y","qty":2}]}`, []string{"id", "items", "name", "qty", "sku", "user"}},
+		{`[{"a":1},{"b":2}]`, []string{"a", "b"}},
+		{`"string"`, nil},
+		{`not json`, nil},
+	}
+
+	for _, test := range tests {
+		names := jsonParameterNames([]byte(test.body))
+
+		slices.Sort(names)
+
+		if !slices.Equal(names, test.names) {
+			t.Errorf("jsonParameterNames(%q) = %q, want %q", test.body, names, test.names)
+		}
+	}
+}
+
+func TestJavaScriptParameterNames(t *testing.T) {
+	tests := []struct {
+		body  string
+		names []string
+	}{
+		{`params.append("page", 1); query.set('sort', s); formData.append("file", f);`, []string{"page", "sort", "file"}},
+		{"body.set(`token`, t); params . append( \"page\" , 2);", []string{"token", "page"}},
+		{`headers.append("X-Token", t); params.get("page");`, nil},
+	}
+
+	for _, test := range tests {
+		if names := javaScriptParameterNames(test.body); !slices.Equal(names, test.names) {
+			t.Errorf("javaScriptParameterNames(%q) = %q, want %q", test.body, names, test.names)
+		}
+	}
+}
+
+func TestParametersAdd(t *testing.T) {
+	p := newParameters()
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		added int
+	)
+
+	for range 10 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if p.Add("https://example.com/search", "q") {
+				mutex.Lock()
+
+				added++
+
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if added != 1 {
+		t.Errorf("Add() added the same parameter %d times, want 1", added)
+	}
+
+	if !p.Add("https://example.com/other", "q") || !p.Add("https://example.com/search", "page") {
+		t.Errorf("Add() did not add a new endpoint or parameter")
+	}
+}
+
+func TestIsJSON(t *testing.T) {
+	tests := []struct {
+		path        string
+		contentType string
+		is          bool
+	}{
+		{"/data.json", "", true},
+		{"/api", "application/json; charset=utf-8", true},
+		{"/api", "application/vnd.api+json", true},
+		{"/api", "text/html", false},
+		{"/app.js", "text/javascript", false},
+	}
+
+	for _, test := range tests {
+		u := &url.URL{
+			Path: test.path,
+		}
+
+		if is := isJSON(u, test.contentType); is != test.is {
+			t.Errorf("isJSON(%q, %q) = %t, want %t", test.path, test.contentType, is, test.is)
+		}
+	}
+}
@@ -0,0 +1,837 @@
+package xcrawl3r
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+type ProxyStat struct {
+	URL      string
+	Requests int64
+	Errors   int64
+	Alive    bool
+}
+
+type proxyEntry struct {
+	URL *url.URL
+
+	requests atomic.Int64
+	errors   atomic.Int64
+
+	probe func(ctx context.Context) (err error)
+
+	mutex     sync.Mutex
+	failures  int
+	cooldown  time.Duration
+	down      bool
+	downUntil time.Time
+	probing   bool
+}
+
+// NOTE: A proxy out of rotation is only put back once its cooldown is over and it passes a health
+// check, run in the background, its cooldown doubling each time it does not.
+func (p *proxyEntry) Alive(now time.Time) (alive bool) {
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	if !p.down {
+		alive = true
+
+		return
+	}
+
+	if now.Before(p.downUntil) || p.probing || p.probe == nil {
+		return
+	}
+
+	p.probing = true
+
+	go p.check()
+
+	return
+}
+
+func (p *proxyEntry) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), proxyCheckTimeout)
+
+	defer cancel()
+
+	err := p.probe(ctx)
+
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	p.probing = false
+
+	if err != nil {
+		p.cooldown = min(p.cooldown*2, maxProxyCooldown)
+		p.downUntil = time.Now().Add(p.cooldown)
+
+		return
+	}
+
+	p.down = false
+}
+
+// NOTE: Only failures of the proxy itself count, not those of targets reached through it.
+// Consecutive failures take a proxy out of rotation for a cooldown, and a success restores it.
+func (p *proxyEntry) Observe(err error) {
+	p.requests.Add(1)
+
+	if err != nil && !isProxyFailure(err) {
+		return
+	}
+
+	p.mutex.Lock()
+
+	defer p.mutex.Unlock()
+
+	if err == nil {
+		p.failures = 0
+		p.cooldown = 0
+		p.down = false
+
+		return
+	}
+
+	p.errors.Add(1)
+
+	p.failures++
+
+	if p.failures < maxProxyFailures {
+		return
+	}
+
+	p.failures = 0
+	p.cooldown = min(max(p.cooldown*2, minProxyCooldown), maxProxyCooldown)
+	p.down = true
+	p.downUntil = time.Now().Add(p.cooldown)
+}
+
+func (p *proxyEntry) Stat() (stat ProxyStat) {
+	p.mutex.Lock()
+
+	alive := !p.down
+
+	p.mutex.Unlock()
+
+	stat = ProxyStat{
+		URL:      p.URL.Redacted(),
+		Requests: p.requests.Load(),
+		Errors:   p.errors.Load(),
+		Alive:    alive,
+	}
+
+	return
+}
+
+type proxyPool struct {
+	proxies  []*proxyEntry
+	rotation string
+	next     atomic.Uint64
+
+	mutex  sync.Mutex
+	sticky map[string]*stickyProxy
+	swept  time.Time
+}
+
+type stickyProxy struct {
+	entry *proxyEntry
+	used  time.Time
+}
+
+func (p *proxyPool) Pick(host string) (entry *proxyEntry) {
+	now := time.Now()
+
+	alive := slices.DeleteFunc(slices.Clone(p.proxies), func(entry *proxyEntry) bool {
+		return !entry.Alive(now)
+	})
+
+	// NOTE: With every proxy down, all stay in rotation rather than failing requests outright.
+	if len(alive) == 0 {
+		alive = p.proxies
+	}
+
+	switch p.rotation {
+	case ProxyRotationRandom:
+		entry = alive[rand.IntN(len(alive))]
+	case ProxyRotationSticky:
+		p.mutex.Lock()
+
+		defer p.mutex.Unlock()
+
+		// NOTE: Hosts left idle are swept, so that long crawls do not keep every host they touched,
+		// and are assigned a proxy afresh if requested again.
+		if now.Sub(p.swept) > maxStickyIdle {
+			maps.DeleteFunc(p.sticky, func(_ string, sticky *stickyProxy) bool {
+				return now.Sub(sticky.used) > maxStickyIdle
+			})
+
+			p.swept = now
+		}
+
+		if sticky, ok := p.sticky[host]; ok && slices.Contains(alive, sticky.entry) {
+			sticky.used = now
+
+			entry = sticky.entry
+
+			return
+		}
+
+		entry = alive[p.next.Add(1)%uint64(len(alive))]
+
+		p.sticky[host] = &stickyProxy{
+			entry: entry,
+			used:  now,
+		}
+	default:
+		entry = alive[p.next.Add(1)%uint64(len(alive))]
+	}
+
+	return
+}
+
+func newProxyPool(URLs []string, rotation string, entries map[string]*proxyEntry) (pool *proxyPool, err error) {
+	pool = &proxyPool{
+		rotation: rotation,
+		sticky:   map[string]*stickyProxy{},
+	}
+
+	for _, URL := range URLs {
+		URL = strings.TrimSpace(URL)
+
+		if URL == "" {
+			continue
+		}
+
+		entry, ok := entries[URL]
+		if !ok {
+			var parsed *url.URL
+
+			parsed, err = url.Parse(URL)
+			if err != nil {
+				err = fmt.Errorf("error parsing proxy %s: %w", URL, err)
+
+				return
+			}
+
+			if !slices.Contains(proxySchemes, parsed.Scheme) || parsed.Host == "" {
+				err = fmt.Errorf("error parsing proxy %s: %w", URL, ErrInvalidProxy)
+
+				return
+			}
+
+			entry = &proxyEntry{
+				URL: parsed,
+			}
+
+			entries[URL] = entry
+		}
+
+		pool.proxies = append(pool.proxies, entry)
+	}
+
+	return
+}
+
+type ProxyRoute struct {
+	Hosts []string
+	Proxy string
+}
+
+type proxyRoute struct {
+	rules []hostRule
+	pool  *proxyPool
+}
+
+// NOTE: Routes are matched in order, the first matching route's pool (none for `direct`)
+// being used, and the global proxies otherwise.
+func (c *Crawler) routeProxy(ctx context.Context, URL *url.URL) (pool *proxyPool) {
+	pool = c.proxyPool
+
+	host, err := normalizeHost(URL.Hostname())
+	if err != nil {
+		return
+	}
+
+	port := URL.Port()
+
+	if port == "" {
+		port = defaultPorts[URL.Scheme]
+	}
+
+	var (
+		IP       string
+		resolved bool
+	)
+
+	for _, route := range c.proxyRoutes {
+		for _, rule := range route.rules {
+			match := rule.Match(host)
+
+			// NOTE: CIDR rules also match hostnames resolving within them, looked up at most once per request.
+			if !match && rule.network != nil && net.ParseIP(host) == nil {
+				if !resolved {
+					IP = c.routeLookup(ctx, net.JoinHostPort(host, port))
+
+					resolved = true
+				}
+
+				match = IP != "" && rule.Match(IP)
+			}
+
+			if match {
+				pool = route.pool
+
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// NOTE: Lookups go through the overrides and the resolvers as dials do, cached per host, so that
+// routing does not resolve hosts on every request.
+func (c *Crawler) routeLookup(ctx context.Context, address string) (IP string) {
+	now := time.Now()
+
+	var ok bool
+
+	if IP, ok = c.routeLookups.Get(address, now); ok {
+		return
+	}
+
+	resolved, err := c.lookup(ctx, address)
+	if err == nil {
+		IP, _, _ = net.SplitHostPort(resolved)
+	}
+
+	// NOTE: Lookups cut short by the request are not cached, as they say nothing of the host.
+	if ctx.Err() != nil {
+		return
+	}
+
+	c.routeLookups.Set(address, IP, now)
+
+	return
+}
+
+type routeLookups struct {
+	mutex   sync.Mutex
+	entries map[string]routeLookup
+	swept   time.Time
+}
+
+type routeLookup struct {
+	IP      string
+	expires time.Time
+}
+
+func (l *routeLookups) Get(address string, now time.Time) (IP string, ok bool) {
+	l.mutex.Lock()
+
+	defer l.mutex.Unlock()
+
+	lookup, ok := l.entries[address]
+
+	if ok && now.After(lookup.expires) {
+		ok = false
+	}
+
+	IP = lookup.IP
+
+	return
+}
+
+// NOTE: Failed lookups are cached too, and expired entries swept as new ones are stored.
+func (l *routeLookups) Set(address, IP string, now time.Time) {
+	l.mutex.Lock()
+
+	defer l.mutex.Unlock()
+
+	if l.entries == nil {
+		l.entries = map[string]routeLookup{}
+	}
+
+	if now.Sub(l.swept) > routeLookupTTL {
+		maps.DeleteFunc(l.entries, func(_ string, lookup routeLookup) bool {
+			return now.After(lookup.expires)
+		})
+
+		l.swept = now
+	}
+
+	l.entries[address] = routeLookup{
+		IP:      IP,
+		expires: now.Add(routeLookupTTL),
+	}
+}
+
+func newProxyRoutes(routes []ProxyRoute, pools map[string][]string, rotation string, entries map[string]*proxyEntry) (parsed []proxyRoute, err error) {
+	named := map[string]*proxyPool{}
+
+	for _, name := range slices.Sorted(maps.Keys(pools)) {
+		named[name], err = newProxyPool(pools[name], rotation, entries)
+		if err != nil {
+			err = fmt.Errorf("error parsing proxy pool %s: %w", name, err)
+
+			return
+		}
+	}
+
+	for _, route := range routes {
+		target := strings.TrimSpace(route.Proxy)
+
+		var pool *proxyPool
+
+		switch {
+		case target == ProxyDirect:
+		case strings.Contains(target, "://"):
+			pool, err = newProxyPool([]string{target}, rotation, entries)
+			if err != nil {
+				return
+			}
+		default:
+			var ok bool
+
+			if pool, ok = named[target]; !ok {
+				err = fmt.Errorf("error parsing proxy route %s: %w", target, ErrUnknownProxyPool)
+
+				return
+			}
+		}
+
+		if len(route.Hosts) == 0 {
+			err = fmt.Errorf("error parsing proxy route %s: %w", target, ErrInvalidProxyRoute)
+
+			return
+		}
+
+		parsedRoute := proxyRoute{
+			pool: pool,
+		}
+
+		for _, host := range route.Hosts {
+			var rule hostRule
+
+			rule, err = parseHostRule(strings.ToLower(strings.TrimSpace(host)))
+			if err != nil {
+				err = fmt.Errorf("error parsing proxy route host %s: %w", host, err)
+
+				return
+			}
+
+			parsedRoute.rules = append(parsedRoute.rules, rule)
+		}
+
+		parsed = append(parsed, parsedRoute)
+	}
+
+	return
+}
+
+type proxyTransport struct {
+	direct     http.RoundTripper
+	transports map[*proxyEntry]http.RoundTripper
+	route      func(ctx context.Context, URL *url.URL) (pool *proxyPool)
+}
+
+// NOTE: Each proxy has its own transport, so connections (and connection pools) never mix
+// proxies, and a request's egress is the proxy it picked.
+func (t *proxyTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	pool := t.route(req.Context(), req.URL)
+
+	if pool == nil || len(pool.proxies) == 0 {
+		res, err = t.direct.RoundTrip(req)
+
+		return
+	}
+
+	entry := pool.Pick(strings.ToLower(req.URL.Hostname()))
+
+	res, err = t.transports[entry].RoundTrip(req)
+
+	observed := err
+
+	// NOTE: Plain HTTP requests get the proxy's 407 as their response, CONNECTs as an error.
+	if err == nil && res.StatusCode == http.StatusProxyAuthRequired {
+		observed = &proxyError{
+			err: ErrProxyAuthenticationRequired,
+		}
+	}
+
+	entry.Observe(observed)
+
+	return
+}
+
+type proxyError struct {
+	err error
+}
+
+func (e *proxyError) Error() (message string) {
+	message = "error connecting through proxy: " + e.err.Error()
+
+	return
+}
+
+func (e *proxyError) Unwrap() (err error) {
+	err = e.err
+
+	return
+}
+
+func isProxyFailure(err error) (failure bool) {
+	var (
+		proxyErr *proxyError
+		opErr    *net.OpError
+	)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+	case errors.As(err, &proxyErr):
+		failure = true
+	case errors.As(err, &opErr) && opErr.Op == "proxyconnect":
+		// NOTE: The transport's errors dialing, or handshaking with, HTTP(S) proxies.
+		failure = true
+	}
+
+	return
+}
+
+func proxyConnectResponse(_ context.Context, _ *url.URL, _ *http.Request, res *http.Response) (err error) {
+	if res.StatusCode == http.StatusProxyAuthRequired {
+		err = &proxyError{
+			err: ErrProxyAuthenticationRequired,
+		}
+	}
+
+	return
+}
+
+// NOTE: HTTPS proxies' own TLS connections are dialed by the crawler, so that they are sent
+// the proxy's name, not the SNI override meant for the target.
+func (c *Crawler) proxyTLSDialer(entry *proxyEntry, dial dialFunc) (wrapped dialFunc) {
+	wrapped = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		conn, err = dial(ctx, network, address)
+		if err != nil {
+			return
+		}
+
+		config := c.tlsConfig.Clone()
+
+		config.ServerName = entry.URL.Hostname()
+
+		client := tls.Client(conn, config)
+
+		if err = client.HandshakeContext(ctx); err != nil {
+			conn.Close()
+
+			return
+		}
+
+		conn = client
+
+		return
+	}
+
+	return
+}
+
+// NOTE: SOCKS proxies are dialed by the crawler, telling failures of the proxy (dialing it,
+// its handshake) from targets it could not reach. socks5 hosts are resolved locally, socks5h
+// ones by the proxy.
+func (c *Crawler) socksDialer(entry *proxyEntry, dial dialFunc) (wrapped dialFunc) {
+	var auth *proxy.Auth
+
+	if entry.URL.User != nil {
+		password, _ := entry.URL.User.Password()
+
+		auth = &proxy.Auth{
+			User:     entry.URL.User.Username(),
+			Password: password,
+		}
+	}
+
+	forward := forwardDialer(func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		conn, err = dial(ctx, network, address)
+		if err != nil {
+			err = &proxyError{
+				err: err,
+			}
+		}
+
+		return
+	})
+
+	wrapped = func(ctx context.Context, network, address string) (conn net.Conn, err error) {
+		if entry.URL.Scheme == "socks5" {
+			address, err = c.lookup(ctx, address)
+			if err != nil {
+				return
+			}
+		}
+
+		var dialer proxy.Dialer
+
+		dialer, err = proxy.SOCKS5("tcp", proxyAddress(entry.URL), auth, forward)
+		if err != nil {
+			return
+		}
+
+		conn, err = dialer.(proxy.ContextDialer).DialContext(ctx, network, address)
+		if err == nil || isProxyFailure(err) || isSOCKSTargetFailure(err) {
+			return
+		}
+
+		if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			err = &proxyError{
+				err: err,
+			}
+		}
+
+		return
+	}
+
+	return
+}
+
+func isSOCKSTargetFailure(err error) (failure bool) {
+	message := err.Error()
+
+	for _, reply := range socksTargetReplies {
+		if strings.HasSuffix(message, reply) {
+			failure = true
+
+			return
+		}
+	}
+
+	return
+}
+
+// NOTE: Health checks dial the proxy, handshaking with it (TLS for HTTPS proxies, SOCKS
+// authentication for SOCKS ones).
+func (c *Crawler) proxyProbe(entry *proxyEntry, dial dialFunc) (probe func(ctx context.Context) (err error)) {
+	probe = func(ctx context.Context) (err error) {
+		var conn net.Conn
+
+		conn, err = dial(ctx, "tcp", proxyAddress(entry.URL))
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+
+		switch entry.URL.Scheme {
+		case "https":
+			config := c.tlsConfig.Clone()
+
+			config.ServerName = entry.URL.Hostname()
+
+			err = tls.Client(conn, config).HandshakeContext(ctx)
+		case "socks5", "socks5h":
+			err = socksHandshake(conn, entry.URL.User)
+		}
+
+		return
+	}
+
+	return
+}
+
+func socksHandshake(conn net.Conn, user *url.Userinfo) (err error) {
+	methods := []byte{socksNoAuth}
+
+	if user != nil {
+		methods = append(methods, socksUserPassAuth)
+	}
+
+	if _, err = conn.Write(append([]byte{socksVersion, byte(len(methods))}, methods...)); err != nil {
+		return
+	}
+
+	reply := make([]byte, 2)
+
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		return
+	}
+
+	if reply[0] != socksVersion || !slices.Contains(methods, reply[1]) {
+		err = ErrProxyHandshake
+
+		return
+	}
+
+	if reply[1] != socksUserPassAuth {
+		return
+	}
+
+	username := user.Username()
+	password, _ := user.Password()
+
+	request := []byte{socksUserPassVersion, byte(len(username))}
+	request = append(request, username...)
+	request = append(request, byte(len(password)))
+	request = append(request, password...)
+
+	if _, err = conn.Write(request); err != nil {
+		return
+	}
+
+	if _, err = io.ReadFull(conn, reply); err != nil {
+		return
+	}
+
+	if reply[1] != 0 {
+		err = ErrProxyAuthenticationFailed
+	}
+
+	return
+}
+
+func proxyAddress(u *url.URL) (address string) {
+	port := u.Port()
+
+	if port == "" {
+		port = proxyDefaultPorts[u.Scheme]
+	}
+
+	address = net.JoinHostPort(u.Hostname(), port)
+
+	return
+}
+
+func (c *Crawler) lookup(ctx context.Context, address string) (resolved string, err error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return
+	}
+
+	if IPs := c.resolve(host, port); len(IPs) > 0 {
+		resolved = net.JoinHostPort(IPs[0], port)
+
+		return
+	}
+
+	if IP := net.ParseIP(host); IP != nil {
+		resolved = address
+
+		return
+	}
+
+	resolver := c.resolver
+
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	var addresses []string
+
+	addresses, err = resolver.LookupHost(ctx, host)
+	if err != nil {
+		return
+	}
+
+	resolved = net.JoinHostPort(addresses[0], port)
+
+	return
+}
+
+func (c *Crawler) ProxyStats() (stats []ProxyStat) {
+	for _, URL := range slices.Sorted(maps.Keys(c.proxies)) {
+		stats = append(stats, c.proxies[URL].Stat())
+	}
+
+	return
+}
+
+type forwardDialer dialFunc
+
+func (d forwardDialer) Dial(network, address string) (conn net.Conn, err error) {
+	conn, err = d(context.Background(), network, address)
+
+	return
+}
+
+func (d forwardDialer) DialContext(ctx context.Context, network, address string) (conn net.Conn, err error) {
+	conn, err = d(ctx, network, address)
+
+	return
+}
+
+const (
+	ProxyRotationRoundRobin = "round-robin"
+	ProxyRotationRandom     = "random"
+	ProxyRotationSticky     = "sticky"
+
+	ProxyDirect = "direct"
+
+	maxProxyFailures  = 3
+	minProxyCooldown  = 30 * time.Second
+	maxProxyCooldown  = 10 * time.Minute
+	proxyCheckTimeout = 10 * time.Second
+	routeLookupTTL    = time.Minute
+	maxStickyIdle     = 10 * time.Minute
+
+	socksVersion         = 0x05
+	socksNoAuth          = 0x00
+	socksUserPassAuth    = 0x02
+	socksUserPassVersion = 0x01
+)
+
+var (
+	ProxyRotations = []string{
+		ProxyRotationRoundRobin,
+		ProxyRotationRandom,
+		ProxyRotationSticky,
+	}
+
+	proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+	proxyDefaultPorts = map[string]string{
+		"http":    "80",
+		"https":   "443",
+		"socks5":  "1080",
+		"socks5h": "1080",
+	}
+
+	// NOTE: SOCKS replies, as worded by golang.org/x/net/proxy, for targets the proxy could not reach.
+	socksTargetReplies = []string{
+		"network unreachable",
+		"host unreachable",
+		"connection refused",
+		"TTL expired",
+	}
+
+	ErrInvalidProxy         = errors.New("invalid proxy, expected http, https, socks5 or socks5h URL")
+	ErrUnknownProxyRotation = errors.New("unknown proxy rotation")
+	ErrUnknownProxyPool     = errors.New("unknown proxy pool")
+	ErrInvalidProxyRoute    = errors.New("invalid proxy route, expected hosts")
+
+	ErrProxyAuthenticationRequired = errors.New("proxy authentication required")
+	ErrProxyAuthenticationFailed   = errors.New("proxy authentication failed")
+	ErrProxyHandshake              = errors.New("unexpected proxy handshake reply")
+)
@@ -0,0 +1,348 @@
+package xcrawl3r
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsProxyFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		failure bool
+	}{
+		{"proxy dial", &net.OpError{Op: "socks connect", Err: &proxyError{err: syscall.ECONNREFUSED}}, true},
+		{"proxy authentication", fmt.Errorf("Get: %w", &proxyError{err: ErrProxyAuthenticationRequired}), true},
+		{"http proxy dial", &net.OpError{Op: "proxyconnect", Err: syscall.ECONNREFUSED}, true},
+		{"target refused", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, false},
+		{"target eof", io.ErrUnexpectedEOF, false},
+		{"canceled", &proxyError{err: context.Canceled}, false},
+	}
+
+	for _, test := range tests {
+		if failure := isProxyFailure(test.err); failure != test.failure {
+			t.Errorf("isProxyFailure(%s) = %v, want %v", test.name, failure, test.failure)
+		}
+	}
+}
+
+func TestIsSOCKSTargetFailure(t *testing.T) {
+	tests := []struct {
+		err     error
+		failure bool
+	}{
+		{errors.New("socks connect tcp 127.0.0.1:1080->10.0.0.1:80: unknown error connection refused"), true},
+		{errors.New("socks connect tcp 127.0.0.1:1080->10.0.0.1:80: unknown error host unreachable"), true},
+		{errors.New("socks connect tcp 127.0.0.1:1080->10.0.0.1:80: username/password authentication failed"), false},
+		{errors.New("socks connect tcp 127.0.0.1:1080->10.0.0.1:80: unknown error general SOCKS server failure"), false},
+	}
+
+	for _, test := range tests {
+		if failure := isSOCKSTargetFailure(test.err); failure != test.failure {
+			t.Errorf("isSOCKSTargetFailure(%q) = %v, want %v", test.err, failure, test.failure)
+		}
+	}
+}
+
+func TestProxyEntryHealthCheck(t *testing.T) {
+	checked := make(chan error, 1)
+
+	entry := &proxyEntry{
+		URL: &url.URL{Scheme: "http", Host: "127.0.0.1:3128"},
+		probe: func(_ context.Context) (err error) {
+			err = <-checked
+
+			return
+		},
+	}
+
+	failure := &proxyError{err: syscall.ECONNREFUSED}
+
+	// NOTE: Target failures do not count.
+	for range maxProxyFailures {
+		entry.Observe(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED})
+	}
+
+	if !entry.Alive(time.Now()) {
+		t.Fatalf("Alive() = false after target failures, want true")
+	}
+
+	for range maxProxyFailures {
+		entry.Observe(failure)
+	}
+
+	if entry.Alive(time.Now()) {
+		t.Fatalf("Alive() = true after %d proxy failures, want false", maxProxyFailures)
+	}
+
+	// NOTE: Once the cooldown is over, the proxy stays out of rotation until a health check passes.
+	later := time.Now().Add(minProxyCooldown)
+
+	if entry.Alive(later) {
+		t.Fatalf("Alive() = true before a health check, want false")
+	}
+
+	checked <- errors.New("refused")
+
+	waitProbe(t, entry)
+
+	if entry.Alive(later) {
+		t.Fatalf("Alive() = true after a failed health check, want false")
+	}
+
+	if entry.cooldown != 2*minProxyCooldown {
+		t.Errorf("cooldown = %s after a failed health check, want %s", entry.cooldown, 2*minProxyCooldown)
+	}
+
+	muchLater := time.Now().Add(2 * minProxyCooldown)
+
+	entry.Alive(muchLater)
+
+	checked <- nil
+
+	waitProbe(t, entry)
+
+	if !entry.Alive(muchLater) {
+		t.Errorf("Alive() = false after a passed health check, want true")
+	}
+
+	if stat := entry.Stat(); stat.Requests != 2*maxProxyFailures || stat.Errors != maxProxyFailures {
+		t.Errorf("Stat() = %+v, want %d requests & %d errors", stat, 2*maxProxyFailures, maxProxyFailures)
+	}
+}
+
+func TestProxyPoolSticky(t *testing.T) {
+	p, err := newProxyPool([]string{"http://127.0.0.1:3128", "http://127.0.0.1:3129"}, ProxyRotationSticky, map[string]*proxyEntry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := p.Pick("a.example.com")
+
+	for range 5 {
+		if picked := p.Pick("a.example.com"); picked != entry {
+			t.Fatalf("Pick() = %s, want %s for the same host", picked.URL, entry.URL)
+		}
+	}
+
+	if picked := p.Pick("b.example.com"); picked == entry {
+		t.Errorf("Pick() = %s for another host, want the next proxy", picked.URL)
+	}
+
+	// NOTE: Hosts left idle are swept, at most once per idle period.
+	p.sticky["a.example.com"].used = time.Now().Add(-2 * maxStickyIdle)
+	p.swept = time.Now().Add(-2 * maxStickyIdle)
+
+	p.Pick("c.example.com")
+
+	if _, ok := p.sticky["a.example.com"]; ok {
+		t.Errorf("Pick() kept the idle a.example.com, want it swept")
+	}
+
+	if len(p.sticky) != 2 {
+		t.Errorf("Pick() kept %d hosts, want 2", len(p.sticky))
+	}
+}
+
+func TestRouteProxy(t *testing.T) {
+	entries := map[string]*proxyEntry{}
+
+	global, err := newProxyPool([]string{"http://127.0.0.1:3128"}, ProxyRotationRoundRobin, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes, err := newProxyRoutes(
+		[]ProxyRoute{
+			{Hosts: []string{"internal.example.com", "10.0.0.0/8"}, Proxy: ProxyDirect},
+			{Hosts: []string{"*.example.com"}, Proxy: "corporate"},
+			{Hosts: []string{"api.example.org"}, Proxy: "socks5://127.0.0.1:1080"},
+		},
+		map[string][]string{"corporate": {"http://127.0.0.1:8080"}},
+		ProxyRotationRoundRobin,
+		entries,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// NOTE: Overrides keep hostnames matched against CIDR rules off the network.
+	resolves, err := parseResolves([]string{"app.example.net:443:10.1.2.3", "example.org:*:192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Crawler{
+		proxyPool:   global,
+		proxyRoutes: routes,
+		resolves:    resolves,
+	}
+
+	tests := []struct {
+		URL  string
+		pool *proxyPool
+	}{
+		{"https://internal.example.com/", nil},
+		{"https://www.example.com/", routes[1].pool},
+		{"https://API.EXAMPLE.ORG:8443/", routes[2].pool},
+		{"http://10.1.2.3/", nil},
+		{"https://app.example.net/", nil},
+		{"https://example.org/", global},
+	}
+
+	for _, test := range tests {
+		URL, err := url.Parse(test.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if pool := c.routeProxy(context.Background(), URL); pool != test.pool {
+			t.Errorf("routeProxy(%s) = %p, want %p", test.URL, pool, test.pool)
+		}
+	}
+}
+
+func TestRouteProxyLookup(t *testing.T) {
+	routes, err := newProxyRoutes([]ProxyRoute{{Hosts: []string{"10.0.0.0/8"}, Proxy: ProxyDirect}}, nil, ProxyRotationRoundRobin, map[string]*proxyEntry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	global, err := newProxyPool([]string{"http://127.0.0.1:3128"}, ProxyRotationRoundRobin, map[string]*proxyEntry{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dials atomic.Int64
+
+	c := &Crawler{
+		proxyPool:   global,
+		proxyRoutes: routes,
+		resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(_ context.Context, _, _ string) (conn net.Conn, err error) {
+				dials.Add(1)
+
+				err = errors.New("unreachable")
+
+				return
+			},
+		},
+	}
+
+	c.resolves, err = parseResolves([]string{"app.example.net:443:10.1.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	URL, _ := url.Parse("https://app.example.net/")
+
+	if pool := c.routeProxy(context.Background(), URL); pool != nil {
+		t.Fatalf("routeProxy(%s) = %p, want direct", URL, pool)
+	}
+
+	// NOTE: Cached, the lookup holds once the override is gone.
+	c.resolves = nil
+
+	if pool := c.routeProxy(context.Background(), URL); pool != nil {
+		t.Errorf("routeProxy(%s) after the override is gone = %p, want direct", URL, pool)
+	}
+
+	// NOTE: Hosts without overrides are looked up with the configured resolver, once.
+	URL, _ = url.Parse("https://app.example.internal/")
+
+	if pool := c.routeProxy(context.Background(), URL); pool != global {
+		t.Errorf("routeProxy(%s) = %p, want %p", URL, pool, global)
+	}
+
+	resolverDials := dials.Load()
+
+	if resolverDials == 0 {
+		t.Fatalf("routeProxy(%s) did not use the configured resolver", URL)
+	}
+
+	c.routeProxy(context.Background(), URL)
+
+	if dials.Load() != resolverDials {
+		t.Errorf("routeProxy(%s) looked the host up again, want it cached", URL)
+	}
+}
+
+func TestRouteLookups(t *testing.T) {
+	l := &routeLookups{}
+
+	now := time.Now()
+
+	l.Set("example.com:443", "10.0.0.1", now)
+	l.Set("example.org:443", "", now)
+
+	if IP, ok := l.Get("example.com:443", now.Add(routeLookupTTL/2)); !ok || IP != "10.0.0.1" {
+		t.Errorf("Get() = %q, %t, want 10.0.0.1, true", IP, ok)
+	}
+
+	if IP, ok := l.Get("example.org:443", now); !ok || IP != "" {
+		t.Errorf("Get() of a failed lookup = %q, %t, want none, true", IP, ok)
+	}
+
+	if _, ok := l.Get("example.com:443", now.Add(2*routeLookupTTL)); ok {
+		t.Errorf("Get() of an expired lookup ok = true, want false")
+	}
+
+	l.Set("example.net:443", "10.0.0.2", now.Add(2*routeLookupTTL))
+
+	if len(l.entries) != 1 {
+		t.Errorf("Set() kept %d entries, want the expired ones swept", len(l.entries))
+	}
+}
+
+func TestNewProxyRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []ProxyRoute
+		err    error
+	}{
+		{"unknown pool", []ProxyRoute{{Hosts: []string{"example.com"}, Proxy: "missing"}}, ErrUnknownProxyPool},
+		{"no hosts", []ProxyRoute{{Proxy: ProxyDirect}}, ErrInvalidProxyRoute},
+		{"invalid proxy", []ProxyRoute{{Hosts: []string{"example.com"}, Proxy: "ftp://127.0.0.1"}}, ErrInvalidProxy},
+	}
+
+	for _, test := range tests {
+		if _, err := newProxyRoutes(test.routes, nil, ProxyRotationRoundRobin, map[string]*proxyEntry{}); !errors.Is(err, test.err) {
+			t.Errorf("newProxyRoutes(%s) error = %v, want %v", test.name, err, test.err)
+		}
+	}
+
+	if _, err := newProxyRoutes([]ProxyRoute{{Hosts: []string{"a..b"}, Proxy: ProxyDirect}}, nil, ProxyRotationRoundRobin, map[string]*proxyEntry{}); err == nil {
+		t.Errorf("newProxyRoutes() with an invalid host error = nil, want error")
+	}
+}
+
+func waitProbe(t *testing.T, entry *proxyEntry) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+
+	for time.Now().Before(deadline) {
+		entry.mutex.Lock()
+
+		probing := entry.probing
+
+		entry.mutex.Unlock()
+
+		if !probing {
+			return
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	t.Fatalf("health check did not complete")
+}
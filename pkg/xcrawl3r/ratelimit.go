@@ -0,0 +1,278 @@
+package xcrawl3r
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type limiter struct {
+	global *bucket
+	rate   float64
+
+	mutex sync.Mutex
+	hosts map[string]*hostLimiter
+	swept time.Time
+}
+
+func (l *limiter) Wait(ctx context.Context, host string) (err error) {
+	h := l.host(host)
+
+	if h.bucket != nil {
+		if err = sleep(ctx, h.bucket.Reserve()); err != nil {
+			return
+		}
+	}
+
+	if err = sleep(ctx, h.Reserve()); err != nil {
+		return
+	}
+
+	if l.global != nil {
+		err = sleep(ctx, l.global.Reserve())
+	}
+
+	return
+}
+
+func (l *limiter) Observe(host string, statusCode int, headers http.Header) {
+	if statusCode <= 0 {
+		return
+	}
+
+	h := l.host(host)
+
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		h.Throttle(retryAfter(headers.Get("Retry-After")))
+	default:
+		h.Recover()
+	}
+}
+
+func (l *limiter) host(host string) (h *hostLimiter) {
+	l.mutex.Lock()
+
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(l.swept) > maxHostIdle {
+		l.sweep(now)
+	}
+
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostLimiter{}
+
+		if l.rate > 0 {
+			h.bucket = newBucket(l.rate)
+		}
+
+		l.hosts[host] = h
+	}
+
+	h.used = now
+
+	return
+}
+
+// NOTE: Hosts left idle, past any backoff, are dropped, so that long crawls across many hosts
+// do not keep every host they touched. They start afresh if requested again.
+func (l *limiter) sweep(now time.Time) {
+	for host, h := range l.hosts {
+		h.mutex.Lock()
+
+		idle := now.Sub(h.used) > maxHostIdle && now.After(h.next)
+
+		h.mutex.Unlock()
+
+		if idle {
+			delete(l.hosts, host)
+		}
+	}
+
+	l.swept = now
+}
+
+type bucket struct {
+	rate  float64
+	burst float64
+
+	mutex  sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NOTE: Reserve takes a token, returning how long to wait for it to become available.
+func (b *bucket) Reserve() (wait time.Duration) {
+	b.mutex.Lock()
+
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	b.tokens--
+
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+
+	return
+}
+
+func newBucket(rate float64) (b *bucket) {
+	burst := max(1, rate)
+
+	b = &bucket{
+		rate:   rate,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+
+	return
+}
+
+type hostLimiter struct {
+	bucket *bucket
+
+	mutex   sync.Mutex
+	backoff time.Duration
+	next    time.Time
+	used    time.Time
+}
+
+// NOTE: While backing off, requests to the host are spaced by the current backoff.
+func (h *hostLimiter) Reserve() (wait time.Duration) {
+	h.mutex.Lock()
+
+	defer h.mutex.Unlock()
+
+	if h.backoff == 0 && h.next.IsZero() {
+		return
+	}
+
+	now := time.Now()
+
+	at := now
+
+	if h.next.After(at) {
+		at = h.next
+	}
+
+	h.next = at.Add(h.backoff)
+
+	wait = at.Sub(now)
+
+	return
+}
+
+func (h *hostLimiter) Throttle(retryAfter time.Duration) {
+	h.mutex.Lock()
+
+	defer h.mutex.Unlock()
+
+	h.backoff = min(max(h.backoff*2, minBackoff), maxBackoff)
+
+	h.next = time.Now().Add(max(h.backoff, retryAfter))
+}
+
+func (h *hostLimiter) Recover() {
+	h.mutex.Lock()
+
+	defer h.mutex.Unlock()
+
+	if h.backoff == 0 {
+		return
+	}
+
+	h.backoff /= 2
+
+	if h.backoff < minBackoff {
+		h.backoff = 0
+		h.next = time.Time{}
+	}
+}
+
+type limitedTransport struct {
+	transport http.RoundTripper
+	limiter   *limiter
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	if err = t.limiter.Wait(req.Context(), req.URL.Host); err != nil {
+		return
+	}
+
+	res, err = t.transport.RoundTrip(req)
+	if err != nil {
+		return
+	}
+
+	t.limiter.Observe(req.URL.Host, res.StatusCode, res.Header)
+
+	return
+}
+
+func newLimiter(rate, hostRate float64) (l *limiter) {
+	l = &limiter{
+		rate:  hostRate,
+		hosts: map[string]*hostLimiter{},
+	}
+
+	if rate > 0 {
+		l.global = newBucket(rate)
+	}
+
+	return
+}
+
+func retryAfter(value string) (after time.Duration) {
+	value = strings.TrimSpace(value)
+
+	if value == "" {
+		return
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		after = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		after = time.Until(at)
+	}
+
+	after = min(max(after, 0), maxRetryAfter)
+
+	return
+}
+
+func sleep(ctx context.Context, duration time.Duration) (err error) {
+	if duration <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(duration)
+
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	return
+}
+
+const (
+	minBackoff    = 500 * time.Millisecond
+	maxBackoff    = time.Minute
+	maxRetryAfter = 10 * time.Minute
+	maxHostIdle   = 10 * time.Minute
+)
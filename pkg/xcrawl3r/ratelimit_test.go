@@ -0,0 +1,209 @@
+package xcrawl3r
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBucketReserve(t *testing.T) {
+	tests := []struct {
+		rate  float64
+		waits []time.Duration
+	}{
+		{10, []time.Duration{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 100 * time.Millisecond, 200 * time.Millisecond}},
+		{2, []time.Duration{0, 0, 500 * time.Millisecond, time.Second}},
+		{0.5, []time.Duration{0, 2 * time.Second, 4 * time.Second}},
+	}
+
+	for _, test := range tests {
+		b := newBucket(test.rate)
+
+		for i, want := range test.waits {
+			// NOTE: Tolerates refills from the time elapsed between reservations.
+			if wait := b.Reserve(); wait > want || wait < want-50*time.Millisecond {
+				t.Errorf("rate %v: Reserve() #%d = %s, want %s", test.rate, i+1, wait, want)
+			}
+		}
+	}
+}
+
+func TestBucketRefill(t *testing.T) {
+	b := newBucket(100)
+
+	for range 100 {
+		b.Reserve()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if wait := b.Reserve(); wait != 0 {
+		t.Errorf("Reserve() after refill = %s, want 0", wait)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		after time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{" 5 ", 5 * time.Second},
+		{"-5", 0},
+		{"999999", maxRetryAfter},
+		{"soon", 0},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+		{time.Now().Add(time.Hour).UTC().Format(http.TimeFormat), maxRetryAfter},
+	}
+
+	for _, test := range tests {
+		if after := retryAfter(test.value); after != test.after {
+			t.Errorf("retryAfter(%q) = %s, want %s", test.value, after, test.after)
+		}
+	}
+
+	value := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+
+	if after := retryAfter(value); after <= 58*time.Second || after > time.Minute {
+		t.Errorf("retryAfter(%q) = %s, want about 1m", value, after)
+	}
+}
+
+func TestHostLimiterBackoff(t *testing.T) {
+	h := &hostLimiter{}
+
+	if wait := h.Reserve(); wait != 0 {
+		t.Fatalf("Reserve() without backoff = %s, want 0", wait)
+	}
+
+	backoffs := []time.Duration{minBackoff, 2 * minBackoff, 4 * minBackoff}
+
+	for _, backoff := range backoffs {
+		h.Throttle(0)
+
+		if h.backoff != backoff {
+			t.Errorf("Throttle() backoff = %s, want %s", h.backoff, backoff)
+		}
+	}
+
+	for range 20 {
+		h.Throttle(0)
+	}
+
+	if h.backoff != maxBackoff {
+		t.Errorf("Throttle() backoff = %s, want at most %s", h.backoff, maxBackoff)
+	}
+
+	h.Throttle(5 * time.Minute)
+
+	if wait := h.Reserve(); wait < 4*time.Minute {
+		t.Errorf("Reserve() after Retry-After 5m = %s, want about 5m", wait)
+	}
+
+	h = &hostLimiter{}
+
+	h.Throttle(0)
+	h.Throttle(0)
+	h.Recover()
+
+	if h.backoff != minBackoff {
+		t.Errorf("Recover() backoff = %s, want %s", h.backoff, minBackoff)
+	}
+
+	h.Recover()
+
+	if h.backoff != 0 || !h.next.IsZero() {
+		t.Errorf("Recover() backoff = %s, next = %s, want none", h.backoff, h.next)
+	}
+
+	if wait := h.Reserve(); wait != 0 {
+		t.Errorf("Reserve() after recovering = %s, want 0", wait)
+	}
+}
+
+func TestLimiterObserve(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		headers    http.Header
+		throttled  bool
+	}{
+		{http.StatusTooManyRequests, http.Header{}, true},
+		{http.StatusServiceUnavailable, http.Header{"Retry-After": {"1"}}, true},
+		{http.StatusOK, http.Header{}, false},
+		{http.StatusInternalServerError, http.Header{}, false},
+		{0, http.Header{}, false},
+	}
+
+	for _, test := range tests {
+		l := newLimiter(0, 0)
+
+		l.Observe("example.com", test.statusCode, test.headers)
+
+		if throttled := l.host("example.com").backoff > 0; throttled != test.throttled {
+			t.Errorf("Observe(%d) throttled = %t, want %t", test.statusCode, throttled, test.throttled)
+		}
+
+		if other := l.host("other.example.com").backoff; other != 0 {
+			t.Errorf("Observe(%d) throttled another host", test.statusCode)
+		}
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := newLimiter(0, 0)
+
+	l.Observe("example.com", http.StatusTooManyRequests, http.Header{"Retry-After": {"60"}})
+
+	if err := l.Wait(context.Background(), "other.example.com"); err != nil {
+		t.Errorf("Wait() for another host error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+
+	defer cancel()
+
+	if err := l.Wait(ctx, "example.com"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() while backing off error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	l := newLimiter(0, 1)
+
+	now := time.Now()
+
+	l.host("idle.example.com").used = now.Add(-2 * maxHostIdle)
+
+	backingOff := l.host("backing-off.example.com")
+
+	backingOff.used = now.Add(-2 * maxHostIdle)
+	backingOff.next = now.Add(time.Minute)
+
+	l.host("active.example.com")
+
+	// NOTE: Sweeps run as hosts are requested, at most once per idle period.
+	l.swept = now.Add(-2 * maxHostIdle)
+
+	l.host("new.example.com")
+
+	for _, host := range []string{"backing-off.example.com", "active.example.com", "new.example.com"} {
+		if _, ok := l.hosts[host]; !ok {
+			t.Errorf("host() swept %s, want it kept", host)
+		}
+	}
+
+	if _, ok := l.hosts["idle.example.com"]; ok {
+		t.Errorf("host() kept idle.example.com, want it swept")
+	}
+
+	l.host("idle.example.com").used = now.Add(-2 * maxHostIdle)
+
+	l.host("other.example.com")
+
+	if _, ok := l.hosts["idle.example.com"]; !ok {
+		t.Errorf("host() swept again within the idle period")
+	}
+}
@@ -0,0 +1,149 @@
+package xcrawl3r
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+type Exchange struct {
+	Date                  time.Time
+	Request               *http.Request
+	RequestBody           []byte
+	RequestBodyTruncated  bool
+	Response              *http.Response
+	ResponseBody          []byte
+	ResponseBodyTruncated bool
+	ServerIPAddress       string
+}
+
+type Recorder interface {
+	Record(exchange Exchange)
+}
+
+type recordTransport struct {
+	transport http.RoundTripper
+	recorders []Recorder
+	limit     int
+}
+
+// NOTE: Exchanges are recorded once their response body has been read and closed. Failed
+// requests, without a response, are not recorded. Bodies are recorded up to the limit, if any.
+func (t *recordTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	exchange := Exchange{
+		Date:    time.Now(),
+		Request: req,
+	}
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			exchange.RequestBody, exchange.RequestBodyTruncated = t.read(body)
+
+			body.Close()
+		}
+	}
+
+	var mutex sync.Mutex
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Conn == nil {
+				return
+			}
+
+			mutex.Lock()
+
+			defer mutex.Unlock()
+
+			if host, _, err := net.SplitHostPort(info.Conn.RemoteAddr().String()); err == nil {
+				exchange.ServerIPAddress = host
+			}
+		},
+	}
+
+	res, err = t.transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err != nil {
+		return
+	}
+
+	res.Body = &recordBody{
+		ReadCloser: res.Body,
+		limit:      t.limit,
+		done: func(body []byte, truncated bool) {
+			mutex.Lock()
+
+			defer mutex.Unlock()
+
+			exchange.Response = res
+			exchange.ResponseBody = body
+			exchange.ResponseBodyTruncated = truncated
+
+			for _, recorder := range t.recorders {
+				recorder.Record(exchange)
+			}
+		},
+	}
+
+	return
+}
+
+func (t *recordTransport) read(r io.Reader) (body []byte, truncated bool) {
+	if t.limit <= 0 {
+		body, _ = io.ReadAll(r)
+
+		return
+	}
+
+	// NOTE: One byte over the limit is read, to tell truncated bodies apart.
+	body, _ = io.ReadAll(io.LimitReader(r, int64(t.limit)+1))
+
+	if len(body) > t.limit {
+		body, truncated = body[:t.limit], true
+	}
+
+	return
+}
+
+type recordBody struct {
+	io.ReadCloser
+
+	limit     int
+	buffer    bytes.Buffer
+	truncated bool
+	done      func(body []byte, truncated bool)
+	once      sync.Once
+}
+
+func (b *recordBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+
+	remaining := n
+
+	if b.limit > 0 {
+		remaining = min(remaining, b.limit-b.buffer.Len())
+	}
+
+	if remaining < n {
+		b.truncated = true
+	}
+
+	if remaining > 0 {
+		b.buffer.Write(p[:remaining])
+	}
+
+	return
+}
+
+func (b *recordBody) Close() (err error) {
+	err = b.ReadCloser.Close()
+
+	b.once.Do(func() {
+		b.done(b.buffer.Bytes(), b.truncated)
+	})
+
+	return
+}
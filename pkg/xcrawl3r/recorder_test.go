@@ -0,0 +1,131 @@
+package xcrawl3r
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRecordTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "0123456789")
+	}))
+
+	defer server.Close()
+
+	tests := []struct {
+		name              string
+		limit             int
+		requestBody       string
+		requestTruncated  bool
+		responseBody      string
+		responseTruncated bool
+	}{
+		{"unlimited", 0, "q=1&page=2", false, "0123456789", false},
+		{"limited", 4, "q=1&", true, "0123", true},
+		{"within the limit", 10, "q=1&page=2", false, "0123456789", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := &exchangeRecorder{}
+
+			client := &http.Client{
+				Transport: &recordTransport{
+					transport: http.DefaultTransport,
+					recorders: []Recorder{recorder},
+					limit:     test.limit,
+				},
+			}
+
+			res, err := client.Post(server.URL, "application/x-www-form-urlencoded", strings.NewReader("q=1&page=2"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, _ := io.ReadAll(res.Body)
+
+			res.Body.Close()
+
+			// NOTE: Limits apply to the recording, not to the body read by the crawler.
+			if string(body) != "0123456789" {
+				t.Errorf("body = %q, want the full body", body)
+			}
+
+			if len(recorder.exchanges) != 1 {
+				t.Fatalf("recorded %d exchanges, want 1", len(recorder.exchanges))
+			}
+
+			exchange := recorder.exchanges[0]
+
+			if string(exchange.RequestBody) != test.requestBody || exchange.RequestBodyTruncated != test.requestTruncated {
+				t.Errorf("request body = %q (truncated %t), want %q (truncated %t)", exchange.RequestBody, exchange.RequestBodyTruncated, test.requestBody, test.requestTruncated)
+			}
+
+			if string(exchange.ResponseBody) != test.responseBody || exchange.ResponseBodyTruncated != test.responseTruncated {
+				t.Errorf("response body = %q (truncated %t), want %q (truncated %t)", exchange.ResponseBody, exchange.ResponseBodyTruncated, test.responseBody, test.responseTruncated)
+			}
+		})
+	}
+}
+
+func TestCrawlReplayNotRecorded(t *testing.T) {
+	directory := t.TempDir()
+
+	name := filepath.Join(directory, "crawl.har")
+
+	har := `{"log":{"entries":[{"request":{"method":"GET","url":"http://127.0.0.1/"},"response":{"status":200,"headers":[{"name":"Content-Type","value":"text/html"}],"content":{"mimeType":"text/html","text":"<a href=\"/a\">a</a>"}}}]}}`
+
+	if err := os.WriteFile(name, []byte(har), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &exchangeRecorder{}
+
+	crawler, err := New(&Configuration{
+		Domains:        []string{"127.0.0.1"},
+		Timeout:        10,
+		Depth:          2,
+		Parallelism:    2,
+		StateDirectory: directory,
+		Replay:         name,
+		Recorders:      []Recorder{recorder},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer crawler.Close()
+
+	results := 0
+
+	for range crawler.Crawl("http://127.0.0.1/") {
+		results++
+	}
+
+	if results == 0 {
+		t.Fatalf("Crawl() replayed no results")
+	}
+
+	if len(recorder.exchanges) != 0 {
+		t.Errorf("recorded %d replayed exchanges, want none", len(recorder.exchanges))
+	}
+}
+
+type exchangeRecorder struct {
+	mutex     sync.Mutex
+	exchanges []Exchange
+}
+
+func (r *exchangeRecorder) Record(exchange Exchange) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.exchanges = append(r.exchanges, exchange)
+}
@@ -0,0 +1,392 @@
+package xcrawl3r
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type archivedResponse struct {
+	method     string
+	statusCode int
+	proto      string
+	header     http.Header
+	body       []byte
+}
+
+type archive struct {
+	responses map[string]archivedResponse
+	URLs      []string
+}
+
+// NOTE: GET responses are kept over others for the same URL, such as a login form's POST.
+func (a *archive) add(method, URL string, response archivedResponse) {
+	canonical, err := canonicalize(URL, false)
+	if err != nil {
+		return
+	}
+
+	response.method = strings.ToUpper(method)
+
+	if response.method == "" {
+		response.method = http.MethodGet
+	}
+
+	// NOTE: Bodies are kept decoded, as HAR content always is and WARC bodies are on reading, so
+	// encoding headers no longer apply.
+	response.header.Del("Content-Encoding")
+	response.header.Del("Content-Length")
+	response.header.Del("Transfer-Encoding")
+
+	existing, ok := a.responses[canonical]
+	if !ok {
+		a.URLs = append(a.URLs, canonical)
+	}
+
+	if ok && existing.method == http.MethodGet && response.method != http.MethodGet {
+		return
+	}
+
+	a.responses[canonical] = response
+}
+
+func (a *archive) importHAR(data []byte) (err error) {
+	var har harLog
+
+	if err = json.Unmarshal(data, &har); err != nil {
+		return
+	}
+
+	for _, entry := range har.Log.Entries {
+		if entry.Response.Status == 0 {
+			continue
+		}
+
+		response := archivedResponse{
+			statusCode: entry.Response.Status,
+			proto:      entry.Response.HTTPVersion,
+			header:     http.Header{},
+			body:       []byte(entry.Response.Content.Text),
+		}
+
+		for _, header := range entry.Response.Headers {
+			response.header.Add(header.Name, header.Value)
+		}
+
+		if entry.Response.Content.Encoding == "base64" {
+			response.body, err = base64.StdEncoding.DecodeString(entry.Response.Content.Text)
+			if err != nil {
+				err = fmt.Errorf("error decoding %s: %w", entry.Request.URL, err)
+
+				return
+			}
+		}
+
+		a.add(entry.Request.Method, entry.Request.URL, response)
+	}
+
+	return
+}
+
+func (a *archive) importWARC(r io.Reader) (err error) {
+	type record struct {
+		ID       string
+		URL      string
+		response archivedResponse
+	}
+
+	records := []record{}
+	methods := map[string]string{}
+
+	reader := bufio.NewReader(r)
+
+	for {
+		var line string
+
+		line, err = reader.ReadString('\n')
+		if errors.Is(err, io.EOF) && strings.TrimSpace(line) == "" {
+			err = nil
+
+			break
+		}
+
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimSpace(line)
+
+		// NOTE: Records are separated by blank lines.
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, "WARC/") {
+			err = fmt.Errorf("error reading WARC record %q: %w", line, ErrInvalidArchive)
+
+			return
+		}
+
+		var header textproto.MIMEHeader
+
+		header, err = textproto.NewReader(reader).ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+
+		var length int64
+
+		length, err = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+		if err != nil {
+			err = fmt.Errorf("error reading WARC record %s: %w", header.Get("WARC-Record-ID"), ErrInvalidArchive)
+
+			return
+		}
+
+		block := make([]byte, length)
+
+		if _, err = io.ReadFull(reader, block); err != nil {
+			return
+		}
+
+		mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+
+		if mediaType != "application/http" {
+			continue
+		}
+
+		switch header.Get("WARC-Type") {
+		case "request":
+			if req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(block))); err == nil {
+				methods[header.Get("WARC-Concurrent-To")] = req.Method
+			}
+		case "response":
+			if params["msgtype"] != "" && params["msgtype"] != "response" {
+				continue
+			}
+
+			var response archivedResponse
+
+			response, err = readArchivedResponse(block)
+			if err != nil {
+				err = fmt.Errorf("error reading WARC record %s: %w", header.Get("WARC-Record-ID"), err)
+
+				return
+			}
+
+			records = append(records, record{
+				ID:       header.Get("WARC-Record-ID"),
+				URL:      strings.Trim(header.Get("WARC-Target-URI"), "<>"),
+				response: response,
+			})
+		}
+	}
+
+	// NOTE: Request records, carrying the method, may come before or after their response record.
+	for _, record := range records {
+		a.add(methods[record.ID], record.URL, record.response)
+	}
+
+	return
+}
+
+func readArchivedResponse(block []byte) (response archivedResponse, err error) {
+	res, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return
+	}
+
+	err = nil
+
+	// NOTE: Archives of raw traffic keep bodies encoded, archives written by the crawler decoded.
+	if strings.EqualFold(res.Header.Get("Content-Encoding"), "gzip") {
+		if reader, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if decoded, err := io.ReadAll(reader); err == nil {
+				body = decoded
+			}
+		}
+	}
+
+	response = archivedResponse{
+		statusCode: res.StatusCode,
+		proto:      res.Proto,
+		header:     res.Header,
+		body:       body,
+	}
+
+	return
+}
+
+func newArchive(name string) (a *archive, err error) {
+	a = &archive{
+		responses: map[string]archivedResponse{},
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		var reader *gzip.Reader
+
+		reader, err = gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			return
+		}
+	}
+
+	switch {
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")):
+		err = a.importHAR(data)
+	case bytes.HasPrefix(data, []byte("WARC/")):
+		err = a.importWARC(bytes.NewReader(data))
+	default:
+		err = ErrInvalidArchive
+	}
+
+	if err != nil {
+		err = fmt.Errorf("error reading archive %s: %w", name, err)
+	}
+
+	return
+}
+
+type replayTransport struct {
+	archive *archive
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	canonical, err := canonicalize(req.URL.String(), false)
+	if err != nil {
+		return
+	}
+
+	response, ok := t.archive.responses[canonical]
+
+	// NOTE: URLs requested with trailing slashes trimmed may have been archived with one.
+	if !ok {
+		if parsed, err := url.Parse(canonical); err == nil && !strings.HasSuffix(parsed.Path, "/") {
+			parsed.Path += "/"
+
+			if parsed.RawPath != "" {
+				parsed.RawPath += "/"
+			}
+
+			response, ok = t.archive.responses[parsed.String()]
+		}
+	}
+
+	if !ok {
+		err = fmt.Errorf("error replaying %s: %w", req.URL.String(), ErrNotArchived)
+
+		return
+	}
+
+	proto := response.proto
+
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		proto, major, minor = "HTTP/1.1", 1, 1
+	}
+
+	res = &http.Response{
+		Status:        fmt.Sprintf("%d %s", response.statusCode, http.StatusText(response.statusCode)),
+		StatusCode:    response.statusCode,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        response.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(response.body)),
+		ContentLength: int64(len(response.body)),
+		Request:       req,
+	}
+
+	if req.Method == http.MethodHead {
+		res.Body = http.NoBody
+	}
+
+	return
+}
+
+// NOTE: Replaying, targets are the origins of archived responses, in archive order.
+func (c *Crawler) ReplayTargets() (targets []string) {
+	if c.archive == nil {
+		return
+	}
+
+	seen := map[string]struct{}{}
+
+	for _, URL := range c.archive.URLs {
+		parsed, err := url.Parse(URL)
+		if err != nil {
+			continue
+		}
+
+		origin := parsed.Scheme + "://" + parsed.Host + "/"
+
+		if _, ok := seen[origin]; ok {
+			continue
+		}
+
+		seen[origin] = struct{}{}
+
+		targets = append(targets, origin)
+	}
+
+	return
+}
+
+func (c *Crawler) archived(target string) (URLs []string) {
+	if c.archive == nil {
+		return
+	}
+
+	parsedTargetURL, err := url.Parse(target)
+	if err != nil {
+		return
+	}
+
+	for _, URL := range c.archive.URLs {
+		if parsed, err := url.Parse(URL); err == nil && parsed.Host == parsedTargetURL.Host {
+			URLs = append(URLs, URL)
+		}
+	}
+
+	return
+}
+
+var (
+	ErrInvalidArchive = errors.New("invalid archive, expected HAR or WARC")
+	ErrNotArchived    = errors.New("not archived")
+)
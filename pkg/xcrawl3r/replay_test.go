@@ -0,0 +1,197 @@
+package xcrawl3r_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hueristiq/xcrawl3r/internal/output"
+	"github.com/hueristiq/xcrawl3r/pkg/xcrawl3r"
+)
+
+func TestWARCReplayRoundTrip(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Header().Set("Set-Cookie", "session=abc; Path=/")
+
+			io.WriteString(w, `<a href="/login">login</a>`)
+		case "/login":
+			if r.Method == http.MethodPost {
+				w.Header().Set("Location", "/")
+				w.WriteHeader(http.StatusFound)
+
+				return
+			}
+
+			w.Header().Set("Content-Type", "text/html")
+
+			io.WriteString(w, `<form method="post"></form>`)
+		case "/binary":
+			w.Header().Set("Content-Type", "application/octet-stream")
+
+			w.Write([]byte{0x00, 0xff, 0x1f, 0x8b, '\r', '\n'})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		compress bool
+		file     string
+	}{
+		{"plain", false, "crawl.warc"},
+		{"gzip", true, "crawl.warc.gz"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directory := t.TempDir()
+
+			writer, err := output.NewWARCWriter(filepath.Join(directory, test.file), test.compress, 0)
+			if err != nil {
+				t.Fatalf("NewWARCWriter() error: %v", err)
+			}
+
+			exchanges := []struct {
+				method string
+				path   string
+				body   string
+			}{
+				{http.MethodGet, "/", ""},
+				{http.MethodGet, "/login", ""},
+				{http.MethodPost, "/login", "user=a&password=b"},
+				{http.MethodGet, "/binary", ""},
+				{http.MethodGet, "/missing", ""},
+			}
+
+			client := &http.Client{
+				CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+					return http.ErrUseLastResponse
+				},
+			}
+
+			live := map[string][]byte{}
+
+			for _, exchange := range exchanges {
+				req, err := http.NewRequest(exchange.method, server.URL+exchange.path, strings.NewReader(exchange.body))
+				if err != nil {
+					t.Fatalf("http.NewRequest() error: %v", err)
+				}
+
+				res, err := client.Do(req)
+				if err != nil {
+					t.Fatalf("client.Do() error: %v", err)
+				}
+
+				body, err := io.ReadAll(res.Body)
+
+				res.Body.Close()
+
+				if err != nil {
+					t.Fatalf("reading %s error: %v", exchange.path, err)
+				}
+
+				if exchange.method == http.MethodGet {
+					live[exchange.path] = body
+				}
+
+				writer.Record(xcrawl3r.Exchange{
+					Date:         time.Now(),
+					Request:      req,
+					RequestBody:  []byte(exchange.body),
+					Response:     res,
+					ResponseBody: body,
+				})
+			}
+
+			if err = writer.Close(); err != nil {
+				t.Fatalf("Close() error: %v", err)
+			}
+
+			transport, err := xcrawl3r.NewReplayTransport(filepath.Join(directory, test.file))
+			if err != nil {
+				t.Fatalf("NewReplayTransport() error: %v", err)
+			}
+
+			replays := []struct {
+				path        string
+				statusCode  int
+				contentType string
+			}{
+				{"/", http.StatusOK, "text/html; charset=utf-8"},
+				// NOTE: The GET response is kept over the POST one for the same URL.
+				{"/login", http.StatusOK, "text/html"},
+				{"/binary", http.StatusOK, "application/octet-stream"},
+				{"/missing", http.StatusNotFound, "text/plain; charset=utf-8"},
+			}
+
+			for _, replay := range replays {
+				req, _ := http.NewRequest(http.MethodGet, server.URL+replay.path, nil)
+
+				res, err := transport.RoundTrip(req)
+				if err != nil {
+					t.Fatalf("RoundTrip(%s) error: %v", replay.path, err)
+				}
+
+				body, _ := io.ReadAll(res.Body)
+
+				res.Body.Close()
+
+				if res.StatusCode != replay.statusCode {
+					t.Errorf("RoundTrip(%s) status = %d, want %d", replay.path, res.StatusCode, replay.statusCode)
+				}
+
+				if contentType := res.Header.Get("Content-Type"); contentType != replay.contentType {
+					t.Errorf("RoundTrip(%s) Content-Type = %q, want %q", replay.path, contentType, replay.contentType)
+				}
+
+				if string(body) != string(live[replay.path]) {
+					t.Errorf("RoundTrip(%s) body = %q, want %q", replay.path, body, live[replay.path])
+				}
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/never", nil)
+
+			if _, err = transport.RoundTrip(req); !errors.Is(err, xcrawl3r.ErrNotArchived) {
+				t.Errorf("RoundTrip(/never) error = %v, want %v", err, xcrawl3r.ErrNotArchived)
+			}
+		})
+	}
+}
+
+func TestReplayArchiveFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"urls.txt", "https://example.com/\n", xcrawl3r.ErrInvalidArchive},
+		{"empty.har", `{"log":{"entries":[]}}`, nil},
+		{"broken.warc", "WARC/1.1\r\nWARC-Type: response\r\nContent-Length: x\r\n\r\n", xcrawl3r.ErrInvalidArchive},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), test.name)
+
+			if err := os.WriteFile(name, []byte(test.data), 0o600); err != nil {
+				t.Fatalf("os.WriteFile() error: %v", err)
+			}
+
+			if _, err := xcrawl3r.NewReplayTransport(name); !errors.Is(err, test.err) {
+				t.Errorf("NewReplayTransport() error = %v, want %v", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package xcrawl3r
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"syscall"
+	"time"
+)
+
+type Retry struct {
+	Attempts int
+	Backoff  time.Duration
+	Statuses []int
+	Errors   []string
+}
+
+func (r Retry) Retryable(statusCode int, err error) (retryable bool) {
+	if statusCode > 0 {
+		retryable = slices.Contains(r.Statuses, statusCode)
+
+		return
+	}
+
+	class := ErrorClass(err)
+
+	retryable = class != "" && slices.Contains(r.Errors, class)
+
+	return
+}
+
+// NOTE: Doubled only while under the maximum, as shifting by the attempt overflows.
+func (r Retry) Wait(attempt int) (wait time.Duration) {
+	wait = r.Backoff
+
+	for i := 1; i < attempt && wait < maxRetryBackoff; i++ {
+		wait <<= 1
+	}
+
+	wait = min(wait, maxRetryBackoff)
+
+	return
+}
+
+// NOTE: Zero attempts, as left by callers not setting Retry, is a single attempt.
+func (r Retry) validate() (err error) {
+	if r.Attempts < 0 {
+		err = fmt.Errorf("error parsing retry attempts %d: %w", r.Attempts, ErrInvalidRetryAttempts)
+
+		return
+	}
+
+	for _, class := range r.Errors {
+		if !slices.Contains(ErrorClasses, class) {
+			err = fmt.Errorf("error parsing retry error class %s: %w", class, ErrUnknownErrorClass)
+
+			return
+		}
+	}
+
+	return
+}
+
+func ErrorClass(err error) (class string) {
+	var (
+		netErr net.Error
+		DNSErr *net.DNSError
+	)
+
+	switch {
+	case err == nil:
+	case isCertificateError(err):
+		class = ErrorClassCertificate
+	case errors.As(err, &DNSErr):
+		class = ErrorClassDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		class = ErrorClassTimeout
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE):
+		class = ErrorClassReset
+	case errors.Is(err, syscall.ECONNREFUSED):
+		class = ErrorClassRefused
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		class = ErrorClassEOF
+	}
+
+	return
+}
+
+const (
+	ErrorClassTimeout     = "timeout"
+	ErrorClassReset       = "reset"
+	ErrorClassRefused     = "refused"
+	ErrorClassEOF         = "eof"
+	ErrorClassCertificate = "certificate"
+	ErrorClassDNS         = "dns"
+
+	maxRetryBackoff = 30 * time.Second
+)
+
+var (
+	ErrorClasses = []string{
+		ErrorClassTimeout,
+		ErrorClassReset,
+		ErrorClassRefused,
+		ErrorClassEOF,
+		ErrorClassCertificate,
+		ErrorClassDNS,
+	}
+
+	ErrUnknownErrorClass    = errors.New("unknown error class")
+	ErrInvalidRetryAttempts = errors.New("invalid retry attempts, expected at least 0")
+)
@@ -0,0 +1,66 @@
+package xcrawl3r
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryWait(t *testing.T) {
+	tests := []struct {
+		backoff time.Duration
+		attempt int
+		wait    time.Duration
+	}{
+		{time.Second, 1, time.Second},
+		{time.Second, 2, 2 * time.Second},
+		{time.Second, 4, 8 * time.Second},
+		{time.Second, 6, maxRetryBackoff},
+		{time.Second, 35, maxRetryBackoff},
+		{10 * time.Second, 31, maxRetryBackoff},
+		{time.Second, 1 << 20, maxRetryBackoff},
+		{time.Minute, 1, maxRetryBackoff},
+		{0, 10, 0},
+	}
+
+	for _, test := range tests {
+		r := Retry{
+			Backoff: test.backoff,
+		}
+
+		if wait := r.Wait(test.attempt); wait != test.wait {
+			t.Errorf("Wait(%d) with backoff %s = %s, want %s", test.attempt, test.backoff, wait, test.wait)
+		}
+	}
+}
+
+func TestRetryValidate(t *testing.T) {
+	tests := []struct {
+		retry Retry
+		err   error
+	}{
+		{Retry{Attempts: 1}, nil},
+		{Retry{Attempts: 3, Errors: []string{ErrorClassTimeout, ErrorClassEOF}}, nil},
+		{Retry{}, nil},
+		{Retry{Attempts: 0}, nil},
+		{Retry{Attempts: -1}, ErrInvalidRetryAttempts},
+		{Retry{Attempts: -10}, ErrInvalidRetryAttempts},
+		{Retry{Attempts: 3, Errors: []string{"teapot"}}, ErrUnknownErrorClass},
+	}
+
+	for _, test := range tests {
+		if err := test.retry.validate(); !errors.Is(err, test.err) {
+			t.Errorf("validate(%+v) error = %v, want %v", test.retry, err, test.err)
+		}
+	}
+}
+
+func TestNewZeroRetry(t *testing.T) {
+	cfg := &Configuration{
+		Domains: []string{"example.com"},
+	}
+
+	if _, err := New(cfg); err != nil {
+		t.Errorf("New() with zero Retry error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,214 @@
+package xcrawl3r
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+type robots struct {
+	client  *http.Client
+	headers http.Header
+
+	mutex sync.Mutex
+	hosts map[string]*robotsHost
+}
+
+func (r *robots) Allowed(ctx context.Context, u *url.URL) (allowed bool) {
+	host := r.host(ctx, u)
+
+	if host.data == nil {
+		allowed = true
+
+		return
+	}
+
+	path := u.EscapedPath()
+
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	allowed = host.data.TestAgent(path, r.headers.Get("User-Agent"))
+
+	return
+}
+
+func (r *robots) Wait(ctx context.Context, u *url.URL) (err error) {
+	host := r.host(ctx, u)
+
+	if host.data == nil {
+		return
+	}
+
+	delay := host.data.FindGroup(r.headers.Get("User-Agent")).CrawlDelay
+
+	if delay <= 0 {
+		return
+	}
+
+	host.mutex.Lock()
+
+	at := time.Now()
+
+	if host.next.After(at) {
+		at = host.next
+	}
+
+	host.next = at.Add(delay)
+
+	host.mutex.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	return
+}
+
+func (r *robots) host(ctx context.Context, u *url.URL) (host *robotsHost) {
+	key := u.Scheme + "://" + u.Host
+
+	r.mutex.Lock()
+
+	host, ok := r.hosts[key]
+	if !ok {
+		host = &robotsHost{}
+
+		r.hosts[key] = host
+	}
+
+	r.mutex.Unlock()
+
+	host.once.Do(func() {
+		host.data, host.err = r.fetch(ctx, key+"/robots.txt")
+	})
+
+	return
+}
+
+func (r *robots) fetch(ctx context.Context, URL string) (data *robotstxt.RobotsData, err error) {
+	var req *http.Request
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, URL, http.NoBody)
+	if err != nil {
+		return
+	}
+
+	req.Header = r.headers.Clone()
+
+	var res *http.Response
+
+	res, err = r.client.Do(req)
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	data, err = robotstxt.FromResponse(res)
+
+	return
+}
+
+type robotsHost struct {
+	once sync.Once
+	data *robotstxt.RobotsData
+	err  error
+
+	mutex sync.Mutex
+	next  time.Time
+}
+
+type robotsDirective struct {
+	Name  string
+	Value string
+}
+
+const (
+	robotsDirectiveAllow    = "allow"
+	robotsDirectiveDisallow = "disallow"
+	robotsDirectiveSitemap  = "sitemap"
+)
+
+func newRobots(client *http.Client, headers http.Header) (r *robots) {
+	r = &robots{
+		client:  client,
+		headers: headers,
+		hosts:   map[string]*robotsHost{},
+	}
+
+	return
+}
+
+func parseRobotsDirectives(body []byte) (directives []robotsDirective) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+
+		if value == "" {
+			continue
+		}
+
+		switch name {
+		case robotsDirectiveAllow, robotsDirectiveDisallow:
+			value = robotsPathPrefix(value)
+
+			if value == "" {
+				continue
+			}
+		case robotsDirectiveSitemap:
+		default:
+			continue
+		}
+
+		directives = append(directives, robotsDirective{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	return
+}
+
+func robotsPathPrefix(pattern string) (prefix string) {
+	prefix = pattern
+
+	if i := strings.Index(prefix, "*"); i >= 0 {
+		prefix = prefix[:i]
+	}
+
+	prefix = strings.TrimSuffix(prefix, "$")
+
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = ""
+	}
+
+	return
+}
@@ -0,0 +1,62 @@
+package xcrawl3r
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseRobotsDirectives(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		directives []robotsDirective
+	}{
+		{
+			name: "directives",
+			body: "User-agent: *\nDisallow: /admin/\nAllow: /public\nSitemap: https://example.com/sitemap.xml\n",
+			directives: []robotsDirective{
+				{robotsDirectiveDisallow, "/admin/"},
+				{robotsDirectiveAllow, "/public"},
+				{robotsDirectiveSitemap, "https://example.com/sitemap.xml"},
+			},
+		},
+		{
+			name: "case and spacing",
+			body: "DISALLOW :   /private  \r\nsitemap:https://example.com/s.xml\r\n",
+			directives: []robotsDirective{
+				{robotsDirectiveDisallow, "/private"},
+				{robotsDirectiveSitemap, "https://example.com/s.xml"},
+			},
+		},
+		{
+			name: "comments",
+			body: "# Disallow: /commented\nDisallow: /tmp # temporary files\n",
+			directives: []robotsDirective{
+				{robotsDirectiveDisallow, "/tmp"},
+			},
+		},
+		{
+			name: "wildcards",
+			body: "Disallow: /search*\nDisallow: /*.php$\nDisallow: /end$\nAllow: *\n",
+			directives: []robotsDirective{
+				{robotsDirectiveDisallow, "/search"},
+				{robotsDirectiveDisallow, "/"},
+				{robotsDirectiveDisallow, "/end"},
+			},
+		},
+		{
+			name: "ignored",
+			body: "User-agent: *\nDisallow:\nCrawl-delay: 10\nHost: example.com\nnonsense\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			directives := parseRobotsDirectives([]byte(test.body))
+
+			if !slices.Equal(directives, test.directives) {
+				t.Errorf("parseRobotsDirectives() = %v, want %v", directives, test.directives)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package xcrawl3r
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/idna"
+)
+
+type Scope struct {
+	Hosts          []string
+	ExcludePaths   []string
+	ExcludeRegexes []string
+	Ports          []int
+	Schemes        []string
+}
+
+type scope struct {
+	include []hostRule
+	exclude []hostRule
+
+	excludePaths   []string
+	excludeRegexes []*regexp.Regexp
+
+	ports   map[string]struct{}
+	schemes map[string]struct{}
+}
+
+func (s *scope) Allowed(u *url.URL) (allowed bool) {
+	scheme := strings.ToLower(u.Scheme)
+
+	if _, ok := s.schemes[scheme]; !ok {
+		return
+	}
+
+	host, err := normalizeHost(u.Hostname())
+	if err != nil {
+		return
+	}
+
+	if len(s.ports) > 0 {
+		port := u.Port()
+
+		if port == "" {
+			port = defaultPorts[scheme]
+		}
+
+		if _, ok := s.ports[port]; !ok {
+			return
+		}
+	}
+
+	if len(s.include) > 0 && !matchHostRules(s.include, host) {
+		return
+	}
+
+	if matchHostRules(s.exclude, host) {
+		return
+	}
+
+	path := u.EscapedPath()
+
+	if path == "" {
+		path = "/"
+	}
+
+	for _, prefix := range s.excludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return
+		}
+	}
+
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	for _, regex := range s.excludeRegexes {
+		if regex.MatchString(path) {
+			return
+		}
+	}
+
+	allowed = true
+
+	return
+}
+
+type hostRule struct {
+	host    string
+	pattern *regexp.Regexp
+	network *net.IPNet
+}
+
+func (r hostRule) Match(host string) (match bool) {
+	switch {
+	case r.network != nil:
+		ip := net.ParseIP(host)
+
+		match = ip != nil && r.network.Contains(ip)
+	case r.pattern != nil:
+		match = r.pattern.MatchString(host)
+	default:
+		match = r.host == host
+	}
+
+	return
+}
+
+func newScope(cfg *Configuration) (s *scope, err error) {
+	s = &scope{
+		ports:   map[string]struct{}{},
+		schemes: map[string]struct{}{},
+	}
+
+	hosts := []string{}
+
+	for _, domain := range cfg.Domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+
+		if domain == "" {
+			continue
+		}
+
+		hosts = append(hosts, domain)
+
+		if cfg.IncludeSubdomains {
+			hosts = append(hosts, "*."+domain)
+
+			continue
+		}
+
+		hosts = append(hosts, "www."+domain)
+	}
+
+	hosts = append(hosts, cfg.Scope.Hosts...)
+
+	for _, host := range hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+
+		negated := strings.HasPrefix(host, "!")
+
+		host = strings.TrimSpace(strings.TrimPrefix(host, "!"))
+
+		if host == "" {
+			continue
+		}
+
+		var rule hostRule
+
+		rule, err = parseHostRule(host)
+		if err != nil {
+			err = fmt.Errorf("error parsing scope host %s: %w", host, err)
+
+			return
+		}
+
+		if negated {
+			s.exclude = append(s.exclude, rule)
+
+			continue
+		}
+
+		s.include = append(s.include, rule)
+	}
+
+	for _, prefix := range cfg.Scope.ExcludePaths {
+		if prefix = strings.TrimSpace(prefix); prefix == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(prefix, "/") {
+			prefix = "/" + prefix
+		}
+
+		s.excludePaths = append(s.excludePaths, prefix)
+	}
+
+	for _, pattern := range cfg.Scope.ExcludeRegexes {
+		var regex *regexp.Regexp
+
+		regex, err = regexp.Compile(pattern)
+		if err != nil {
+			err = fmt.Errorf("error parsing scope exclude regex %s: %w", pattern, err)
+
+			return
+		}
+
+		s.excludeRegexes = append(s.excludeRegexes, regex)
+	}
+
+	for _, port := range cfg.Scope.Ports {
+		if port <= 0 || port > 65535 {
+			err = fmt.Errorf("error parsing scope port %d: %w", port, ErrInvalidPort)
+
+			return
+		}
+
+		s.ports[strconv.Itoa(port)] = struct{}{}
+	}
+
+	schemes := cfg.Scope.Schemes
+
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+
+	for _, scheme := range schemes {
+		s.schemes[strings.ToLower(strings.TrimSpace(scheme))] = struct{}{}
+	}
+
+	return
+}
+
+func parseHostRule(host string) (rule hostRule, err error) {
+	if strings.Contains(host, "/") {
+		_, rule.network, err = net.ParseCIDR(host)
+
+		return
+	}
+
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		bits := 8 * net.IPv6len
+
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 8*net.IPv4len
+		}
+
+		rule.network = &net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(bits, bits),
+		}
+
+		return
+	}
+
+	if !strings.Contains(host, "*") {
+		rule.host, err = normalizeHost(host)
+
+		return
+	}
+
+	if host == "*" {
+		rule.pattern = regexp.MustCompile(`.*`)
+
+		return
+	}
+
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	// NOTE: A leading `*.` matches one or more labels, other `*` match within a label.
+	if strings.HasPrefix(host, "*.") {
+		b.WriteString(`(?:[^.]+\.)+`)
+
+		host = host[2:]
+	}
+
+	labels := strings.Split(host, ".")
+
+	for i, label := range labels {
+		if i > 0 {
+			b.WriteString(`\.`)
+		}
+
+		if !strings.Contains(label, "*") {
+			label, err = normalizeHost(label)
+			if err != nil {
+				return
+			}
+
+			b.WriteString(regexp.QuoteMeta(label))
+
+			continue
+		}
+
+		for j, part := range strings.Split(label, "*") {
+			if j > 0 {
+				b.WriteString(`[^.]*`)
+			}
+
+			b.WriteString(regexp.QuoteMeta(part))
+		}
+	}
+
+	b.WriteString("$")
+
+	rule.pattern, err = regexp.Compile(b.String())
+
+	return
+}
+
+func normalizeHost(host string) (normalized string, err error) {
+	host = strings.TrimSuffix(strings.Trim(strings.TrimSpace(host), "[]"), ".")
+
+	if host == "" {
+		err = ErrInvalidHost
+
+		return
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		normalized = ip.String()
+
+		return
+	}
+
+	normalized, err = idna.Lookup.ToASCII(host)
+	if err != nil && !strings.ContainsFunc(host, func(r rune) bool { return r > unicode.MaxASCII }) {
+		// NOTE: IDNA rejects underscores, common in internal hostnames.
+		normalized, err = strings.ToLower(host), nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	for _, label := range strings.Split(normalized, ".") {
+		if label == "" || strings.ContainsFunc(label, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+		}) {
+			normalized, err = "", ErrInvalidHost
+
+			return
+		}
+	}
+
+	return
+}
+
+func matchHostRules(rules []hostRule, host string) (match bool) {
+	for _, rule := range rules {
+		if match = rule.Match(host); match {
+			return
+		}
+	}
+
+	return
+}
+
+var (
+	defaultPorts = map[string]string{
+		"http":  "80",
+		"https": "443",
+	}
+
+	ErrInvalidHost = errors.New("invalid host")
+	ErrInvalidPort = errors.New("invalid port")
+	ErrOutOfScope  = errors.New("out of scope")
+)
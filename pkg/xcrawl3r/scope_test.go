@@ -0,0 +1,214 @@
+package xcrawl3r
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseHostRule(t *testing.T) {
+	tests := []struct {
+		rule  string
+		host  string
+		match bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com", "www.example.com", false},
+		{"Example.COM.", "example.com", true},
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "example.com.evil.com", false},
+		{"api-*.example.com", "api-v2.example.com", true},
+		{"api-*.example.com", "api.example.com", false},
+		{"api-*.example.com", "x.api-v2.example.com", false},
+		{"*", "anything.example", true},
+		{"10.0.0.0/8", "10.1.2.3", true},
+		{"10.0.0.0/8", "11.1.2.3", false},
+		{"10.0.0.0/8", "ten.example.com", false},
+		{"192.168.1.1", "192.168.1.1", true},
+		{"192.168.1.1", "192.168.1.2", false},
+		{"[2001:db8::1]", "2001:db8::1", true},
+		{"2001:db8::/32", "2001:db8::42", true},
+		{"bücher.example", "xn--bcher-kva.example", true},
+		{"*.bücher.example", "shop.xn--bcher-kva.example", true},
+		{"internal_host", "internal_host", true},
+	}
+
+	for _, test := range tests {
+		rule, err := parseHostRule(test.rule)
+		if err != nil {
+			t.Errorf("parseHostRule(%q) error: %v", test.rule, err)
+
+			continue
+		}
+
+		if match := rule.Match(test.host); match != test.match {
+			t.Errorf("parseHostRule(%q).Match(%q) = %v, want %v", test.rule, test.host, match, test.match)
+		}
+	}
+}
+
+func TestParseHostRuleInvalid(t *testing.T) {
+	tests := []string{
+		"10.0.0.0/33",
+		"exa mple.com",
+		"example..com",
+	}
+
+	for _, test := range tests {
+		if _, err := parseHostRule(test); err == nil {
+			t.Errorf("parseHostRule(%q) error = nil, want error", test)
+		}
+	}
+}
+
+func TestScopeAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Configuration
+		URL     string
+		allowed bool
+	}{
+		{
+			name:    "domain",
+			cfg:     Configuration{Domains: []string{"example.com"}},
+			URL:     "https://example.com/",
+			allowed: true,
+		},
+		{
+			name:    "domain www",
+			cfg:     Configuration{Domains: []string{"example.com"}},
+			URL:     "https://www.example.com/",
+			allowed: true,
+		},
+		{
+			name: "domain subdomain",
+			cfg:  Configuration{Domains: []string{"example.com"}},
+			URL:  "https://api.example.com/",
+		},
+		{
+			name:    "domain include subdomains",
+			cfg:     Configuration{Domains: []string{"example.com"}, IncludeSubdomains: true},
+			URL:     "https://a.b.example.com/",
+			allowed: true,
+		},
+		{
+			name: "other domain",
+			cfg:  Configuration{Domains: []string{"example.com"}, IncludeSubdomains: true},
+			URL:  "https://example.org/",
+		},
+		{
+			name: "negated host",
+			cfg: Configuration{Domains: []string{"example.com"}, IncludeSubdomains: true, Scope: Scope{
+				Hosts: []string{"!admin.example.com"},
+			}},
+			URL: "https://admin.example.com/",
+		},
+		{
+			name: "negated wildcard",
+			cfg: Configuration{Scope: Scope{
+				Hosts: []string{"*.example.com", "!*.internal.example.com"},
+			}},
+			URL: "https://db.internal.example.com/",
+		},
+		{
+			name: "cidr",
+			cfg: Configuration{Scope: Scope{
+				Hosts: []string{"10.0.0.0/8"},
+			}},
+			URL:     "http://10.20.30.40:8080/",
+			allowed: true,
+		},
+		{
+			name: "ipv6",
+			cfg: Configuration{Scope: Scope{
+				Hosts: []string{"2001:db8::/32"},
+			}},
+			URL:     "http://[2001:db8::1]/",
+			allowed: true,
+		},
+		{
+			name: "idn unicode",
+			cfg: Configuration{Scope: Scope{
+				Hosts: []string{"bücher.example"},
+			}},
+			URL:     "https://BÜCHER.example/",
+			allowed: true,
+		},
+		{
+			name: "idn punycode",
+			cfg: Configuration{Scope: Scope{
+				Hosts: []string{"bücher.example"},
+			}},
+			URL:     "https://xn--bcher-kva.example/",
+			allowed: true,
+		},
+		{
+			name: "port default",
+			cfg: Configuration{Domains: []string{"example.com"}, Scope: Scope{
+				Ports: []int{443},
+			}},
+			URL:     "https://example.com/",
+			allowed: true,
+		},
+		{
+			name: "port other",
+			cfg: Configuration{Domains: []string{"example.com"}, Scope: Scope{
+				Ports: []int{443},
+			}},
+			URL: "https://example.com:8443/",
+		},
+		{
+			name: "scheme default",
+			cfg:  Configuration{Domains: []string{"example.com"}},
+			URL:  "ftp://example.com/",
+		},
+		{
+			name: "scheme configured",
+			cfg: Configuration{Domains: []string{"example.com"}, Scope: Scope{
+				Schemes: []string{"https"},
+			}},
+			URL: "http://example.com/",
+		},
+		{
+			name: "exclude path",
+			cfg: Configuration{Domains: []string{"example.com"}, Scope: Scope{
+				ExcludePaths: []string{"logout"},
+			}},
+			URL: "https://example.com/logout?next=/",
+		},
+		{
+			name: "exclude regex",
+			cfg: Configuration{Domains: []string{"example.com"}, Scope: Scope{
+				ExcludeRegexes: []string{`[?&]action=delete`},
+			}},
+			URL: "https://example.com/item?id=1&action=delete",
+		},
+		{
+			name: "exclude regex no match",
+			cfg: Configuration{Domains: []string{"example.com"}, Scope: Scope{
+				ExcludeRegexes: []string{`[?&]action=delete`},
+			}},
+			URL:     "https://example.com/item?id=1",
+			allowed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := newScope(&test.cfg)
+			if err != nil {
+				t.Fatalf("newScope() error: %v", err)
+			}
+
+			u, err := url.Parse(test.URL)
+			if err != nil {
+				t.Fatalf("url.Parse(%q) error: %v", test.URL, err)
+			}
+
+			if allowed := s.Allowed(u); allowed != test.allowed {
+				t.Errorf("Allowed(%q) = %v, want %v", test.URL, allowed, test.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package xcrawl3r
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+type sitemapEntry struct {
+	URL          string
+	LastModified string
+	Tag          string
+	Attribute    string
+	Index        bool
+}
+
+func parseSitemap(body []byte) (entries []sitemapEntry, err error) {
+	if bytes.HasPrefix(body, gzipMagic) {
+		body, err = gunzip(body)
+		if err != nil {
+			return
+		}
+	}
+
+	body = bytes.TrimPrefix(body, utf8BOM)
+	body = bytes.TrimSpace(body)
+
+	if bytes.HasPrefix(body, []byte("<")) {
+		entries, err = parseXMLSitemap(body)
+
+		return
+	}
+
+	entries = parseTextSitemap(body)
+
+	return
+}
+
+func parseXMLSitemap(body []byte) (entries []sitemapEntry, err error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+
+	decoder.Strict = false
+
+	var (
+		root  string
+		entry *sitemapEntry
+		text  strings.Builder
+	)
+
+	for {
+		var token xml.Token
+
+		token, err = decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+
+			if root == "" {
+				root = name
+
+				if _, ok := sitemapRoots[root]; !ok {
+					err = ErrNotSitemap
+
+					return
+				}
+
+				continue
+			}
+
+			text.Reset()
+
+			switch {
+			case entry == nil && sitemapEntryTags[root] == name:
+				entry = &sitemapEntry{
+					Tag:   name,
+					Index: root == "sitemapindex",
+				}
+			case name == "link" && root == "feed" && entry != nil:
+				if href := xmlAttr(t, "href"); href != "" && entry.URL == "" {
+					entry.URL = href
+					entry.Attribute = "href"
+				}
+			case name == "link" && (root == "feed" || root == "urlset"):
+				// NOTE: feed level links and xhtml:link alternates of urlset entries.
+				if href := xmlAttr(t, "href"); href != "" {
+					entries = append(entries, sitemapEntry{
+						URL:       href,
+						Tag:       name,
+						Attribute: "href",
+					})
+				}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			name := t.Name.Local
+			value := strings.TrimSpace(text.String())
+
+			text.Reset()
+
+			if entry == nil {
+				continue
+			}
+
+			switch {
+			case name == sitemapEntryTags[root]:
+				if entry.URL != "" {
+					entries = append(entries, *entry)
+				}
+
+				entry = nil
+			case value == "":
+			case name == "loc" || (name == "link" && root == "rss"):
+				// NOTE: first one wins, e.g. image:loc follows the page loc.
+				if entry.URL == "" {
+					entry.URL = value
+				}
+			case name == "lastmod" || name == "pubDate" || name == "updated":
+				entry.LastModified = value
+			}
+		}
+	}
+
+	return
+}
+
+func parseTextSitemap(body []byte) (entries []sitemapEntry) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if !strings.HasPrefix(line, "http://") && !strings.HasPrefix(line, "https://") {
+			continue
+		}
+
+		entries = append(entries, sitemapEntry{
+			URL: line,
+		})
+	}
+
+	return
+}
+
+func gunzip(compressed []byte) (decompressed []byte, err error) {
+	var reader *gzip.Reader
+
+	reader, err = gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return
+	}
+
+	defer reader.Close()
+
+	decompressed, err = io.ReadAll(reader)
+
+	return
+}
+
+func xmlAttr(element xml.StartElement, name string) (value string) {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			value = strings.TrimSpace(attr.Value)
+
+			return
+		}
+	}
+
+	return
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	utf8BOM   = []byte{0xef, 0xbb, 0xbf}
+
+	sitemapRoots = map[string]struct{}{
+		"urlset":       {},
+		"sitemapindex": {},
+		"rss":          {},
+		"feed":         {},
+	}
+	sitemapEntryTags = map[string]string{
+		"urlset":       "url",
+		"sitemapindex": "sitemap",
+		"rss":          "item",
+		"feed":         "entry",
+	}
+
+	ErrNotSitemap = errors.New("not a sitemap")
+)
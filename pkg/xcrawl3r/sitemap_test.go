@@ -0,0 +1,114 @@
+package xcrawl3r
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseSitemap(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		entries []sitemapEntry
+		err     error
+	}{
+		{
+			name: "urlset",
+			body: `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:image="http://www.google.com/schemas/sitemap-image/1.1" xmlns:xhtml="http://www.w3.org/1999/xhtml">
+	<url>
+		<loc> https://example.com/a </loc>
+		<lastmod>2024-01-02</lastmod>
+		<image:image><image:loc>https://example.com/a.png</image:loc></image:image>
+		<xhtml:link rel="alternate" hreflang="de" href="https://example.com/de/a"/>
+	</url>
+	<url><loc>https://example.com/b</loc></url>
+	<url><lastmod>2024-01-02</lastmod></url>
+</urlset>`,
+			entries: []sitemapEntry{
+				{URL: "https://example.com/de/a", Tag: "link", Attribute: "href"},
+				{URL: "https://example.com/a", LastModified: "2024-01-02", Tag: "url"},
+				{URL: "https://example.com/b", Tag: "url"},
+			},
+		},
+		{
+			name: "sitemapindex",
+			body: `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<sitemap><loc>https://example.com/sitemap-1.xml.gz</loc><lastmod>2024-01-02</lastmod></sitemap>
+</sitemapindex>`,
+			entries: []sitemapEntry{
+				{URL: "https://example.com/sitemap-1.xml.gz", LastModified: "2024-01-02", Tag: "sitemap", Index: true},
+			},
+		},
+		{
+			name: "rss",
+			body: `<rss version="2.0"><channel><link>https://example.com/</link>
+	<item><title>A</title><link>https://example.com/posts/a</link><pubDate>Tue, 02 Jan 2024 00:00:00 GMT</pubDate></item>
+</channel></rss>`,
+			entries: []sitemapEntry{
+				{URL: "https://example.com/posts/a", LastModified: "Tue, 02 Jan 2024 00:00:00 GMT", Tag: "item"},
+			},
+		},
+		{
+			name: "atom",
+			body: `<feed xmlns="http://www.w3.org/2005/Atom"><link href="https://example.com/feed" rel="self"/>
+	<entry><link href="https://example.com/posts/b"/><updated>2024-01-02T00:00:00Z</updated></entry>
+</feed>`,
+			entries: []sitemapEntry{
+				{URL: "https://example.com/feed", Tag: "link", Attribute: "href"},
+				{URL: "https://example.com/posts/b", LastModified: "2024-01-02T00:00:00Z", Tag: "entry", Attribute: "href"},
+			},
+		},
+		{
+			name: "text",
+			body: "\xef\xbb\xbfhttps://example.com/a\n\n  http://example.com/b  \n/relative\nftp://example.com/c\n",
+			entries: []sitemapEntry{
+				{URL: "https://example.com/a"},
+				{URL: "http://example.com/b"},
+			},
+		},
+		{
+			name: "not sitemap",
+			body: "<html><body><a href=\"/a\">a</a></body></html>",
+			err:  ErrNotSitemap,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries, err := parseSitemap([]byte(test.body))
+			if !errors.Is(err, test.err) {
+				t.Fatalf("parseSitemap() error = %v, want %v", err, test.err)
+			}
+
+			if !slices.Equal(entries, test.entries) {
+				t.Errorf("parseSitemap() = %+v, want %+v", entries, test.entries)
+			}
+		})
+	}
+}
+
+func TestParseSitemapGzip(t *testing.T) {
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+
+	writer.Write([]byte("<urlset><url><loc>https://example.com/a</loc></url></urlset>"))
+	writer.Close()
+
+	entries, err := parseSitemap(compressed.Bytes())
+	if err != nil {
+		t.Fatalf("parseSitemap() error: %v", err)
+	}
+
+	want := []sitemapEntry{
+		{URL: "https://example.com/a", Tag: "url"},
+	}
+
+	if !slices.Equal(entries, want) {
+		t.Errorf("parseSitemap() = %+v, want %+v", entries, want)
+	}
+}
@@ -0,0 +1,183 @@
+package xcrawl3r
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type sourceMap struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file"`
+	SourceRoot     string    `json:"sourceRoot"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+	Sections       []struct {
+		Map *sourceMap `json:"map"`
+	} `json:"sections"`
+}
+
+func (m *sourceMap) Files() (files []sourceMapFile) {
+	for i, source := range m.Sources {
+		file := sourceMapFile{
+			Path: source,
+		}
+
+		if m.SourceRoot != "" && !strings.Contains(source, "://") {
+			file.Path = strings.TrimSuffix(m.SourceRoot, "/") + "/" + strings.TrimPrefix(source, "/")
+		}
+
+		if i < len(m.SourcesContent) && m.SourcesContent[i] != nil {
+			file.Content = *m.SourcesContent[i]
+		}
+
+		files = append(files, file)
+	}
+
+	for _, section := range m.Sections {
+		if section.Map != nil {
+			files = append(files, section.Map.Files()...)
+		}
+	}
+
+	return
+}
+
+type sourceMapFile struct {
+	Path    string
+	Content string
+}
+
+func parseSourceMap(body []byte) (m *sourceMap, err error) {
+	body = bytes.TrimSpace(body)
+
+	// NOTE: Strips the XSSI protection prefix, e.g. )]}'
+	if bytes.HasPrefix(body, []byte(")]}")) {
+		if i := bytes.IndexByte(body, '\n'); i >= 0 {
+			body = body[i+1:]
+		}
+	}
+
+	m = &sourceMap{}
+
+	if err = json.Unmarshal(body, m); err != nil {
+		return
+	}
+
+	if len(m.Sources) == 0 && len(m.Sections) == 0 {
+		err = ErrNotSourceMap
+	}
+
+	return
+}
+
+func sourceMappingURL(body string) (URL string) {
+	matches := sourceMappingURLRegex.FindAllStringSubmatch(body, -1)
+
+	if len(matches) > 0 {
+		URL = matches[len(matches)-1][1]
+	}
+
+	return
+}
+
+func decodeInlineSourceMap(URL string) (body []byte, ok bool) {
+	header, data, found := strings.Cut(strings.TrimPrefix(URL, "data:"), ",")
+	if !found {
+		return
+	}
+
+	if !strings.HasSuffix(header, ";base64") {
+		unescaped, err := url.PathUnescape(data)
+		if err != nil {
+			return
+		}
+
+		body, ok = []byte(unescaped), true
+
+		return
+	}
+
+	body, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return
+	}
+
+	ok = true
+
+	return
+}
+
+func sourceMapVariant(URL string) (variant string, ok bool) {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return
+	}
+
+	switch path.Ext(parsed.Path) {
+	case ".js", ".mjs", ".cjs":
+	default:
+		return
+	}
+
+	parsed.Path += ".map"
+	parsed.RawPath = ""
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+
+	variant, ok = parsed.String(), true
+
+	return
+}
+
+func isSourceMap(URL *url.URL) (is bool) {
+	is = path.Ext(URL.Path) == ".map"
+
+	return
+}
+
+func writeSourceMapFile(directory, host string, file sourceMapFile) (err error) {
+	name := file.Path
+
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+
+	name = strings.SplitN(name, "?", 2)[0]
+
+	// NOTE: Cleaning a rooted path drops any leading "..", keeping files inside the directory.
+	name = path.Clean("/" + name)
+
+	if name == "/" {
+		return
+	}
+
+	name = filepath.Join(directory, sanitizeHost(host), filepath.FromSlash(name))
+
+	if err = os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return
+	}
+
+	err = os.WriteFile(name, []byte(file.Content), 0o600)
+
+	return
+}
+
+func sanitizeHost(host string) (sanitized string) {
+	sanitized = strings.NewReplacer(":", "_", "[", "", "]", "").Replace(host)
+
+	return
+}
+
+var (
+	sourceMappingURLRegex = regexp.MustCompile(`[#@]\s*sourceMappingURL=(\S+)`)
+
+	ErrNotSourceMap = errors.New("not a source map")
+)
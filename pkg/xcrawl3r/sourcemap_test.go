@@ -0,0 +1,183 @@
+package xcrawl3r
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseSourceMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		files []sourceMapFile
+		err   bool
+	}{
+		{
+			name: "sources",
+			body: `{"version":3,"sources":["src/a.ts","src/b.ts"],"sourcesContent":["fetch('/api/a')",null]}`,
+			files: []sourceMapFile{
+				{Path: "src/a.ts", Content: "fetch('/api/a')"},
+				{Path: "src/b.ts"},
+			},
+		},
+		{
+			name: "source root",
+			body: `{"version":3,"sourceRoot":"webpack:///","sources":["/src/a.ts","https://cdn.example.com/b.js"]}`,
+			files: []sourceMapFile{
+				{Path: "webpack:///src/a.ts"},
+				{Path: "https://cdn.example.com/b.js"},
+			},
+		},
+		{
+			name: "sections",
+			body: `{"version":3,"sections":[{"offset":{"line":0,"column":0},"map":{"version":3,"sources":["a.js"],"sourcesContent":["a"]}}]}`,
+			files: []sourceMapFile{
+				{Path: "a.js", Content: "a"},
+			},
+		},
+		{
+			name: "XSSI prefix",
+			body: ")]}'\n" + `{"version":3,"sources":["a.js"]}`,
+			files: []sourceMapFile{
+				{Path: "a.js"},
+			},
+		},
+		{
+			name: "not a source map",
+			body: `{"version":3}`,
+			err:  true,
+		},
+		{
+			name: "HTML",
+			body: "<html><body>Not Found</body></html>",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := parseSourceMap([]byte(test.body))
+
+			if test.err {
+				if err == nil {
+					t.Errorf("parseSourceMap() error = nil, want error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parseSourceMap() error = %v", err)
+			}
+
+			if files := m.Files(); !slices.Equal(files, test.files) {
+				t.Errorf("Files() = %+v, want %+v", files, test.files)
+			}
+		})
+	}
+
+	if _, err := parseSourceMap([]byte(`{"version":3}`)); !errors.Is(err, ErrNotSourceMap) {
+		t.Errorf("parseSourceMap() error = %v, want %v", err, ErrNotSourceMap)
+	}
+}
+
+func TestSourceMappingURL(t *testing.T) {
+	tests := []struct {
+		body string
+		URL  string
+	}{
+		{"var a;\n//# sourceMappingURL=app.js.map", "app.js.map"},
+		{"var a;\n//@ sourceMappingURL=old.js.map", "old.js.map"},
+		{"/*# sourceMappingURL=a.map */\n//# sourceMappingURL=b.map", "b.map"},
+		{"var a;", ""},
+	}
+
+	for _, test := range tests {
+		if URL := sourceMappingURL(test.body); URL != test.URL {
+			t.Errorf("sourceMappingURL(%q) = %q, want %q", test.body, URL, test.URL)
+		}
+	}
+}
+
+func TestDecodeInlineSourceMap(t *testing.T) {
+	data := `{"version":3,"sources":["a.js"]}`
+
+	tests := []struct {
+		URL  string
+		body string
+		ok   bool
+	}{
+		{"data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(data)), data, true},
+		{"data:application/json," + "%7B%22version%22%3A3%7D", `{"version":3}`, true},
+		{"data:application/json;base64,!!!", "", false},
+		{"data:application/json", "", false},
+	}
+
+	for _, test := range tests {
+		body, ok := decodeInlineSourceMap(test.URL)
+
+		if ok != test.ok || string(body) != test.body {
+			t.Errorf("decodeInlineSourceMap(%q) = %q, %t, want %q, %t", test.URL, body, ok, test.body, test.ok)
+		}
+	}
+}
+
+func TestSourceMapVariant(t *testing.T) {
+	tests := []struct {
+		URL     string
+		variant string
+		ok      bool
+	}{
+		{"https://example.com/app.js", "https://example.com/app.js.map", true},
+		{"https://example.com/app.mjs?v=1#top", "https://example.com/app.mjs.map", true},
+		{"https://example.com/app.css", "", false},
+		{"https://example.com/", "", false},
+	}
+
+	for _, test := range tests {
+		variant, ok := sourceMapVariant(test.URL)
+
+		if variant != test.variant || ok != test.ok {
+			t.Errorf("sourceMapVariant(%q) = %q, %t, want %q, %t", test.URL, variant, ok, test.variant, test.ok)
+		}
+	}
+}
+
+func TestWriteSourceMapFile(t *testing.T) {
+	directory := t.TempDir()
+
+	tests := []struct {
+		path string
+		name string
+	}{
+		{"webpack:///./src/api/client.ts", "src/api/client.ts"},
+		{"webpack:///../../etc/passwd", "etc/passwd"},
+		{"src/index.ts?v=2", "src/index.ts"},
+	}
+
+	for _, test := range tests {
+		file := sourceMapFile{
+			Path:    test.path,
+			Content: test.path,
+		}
+
+		if err := writeSourceMapFile(directory, "example.com:8080", file); err != nil {
+			t.Fatalf("writeSourceMapFile(%q) error = %v", test.path, err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(directory, "example.com_8080", filepath.FromSlash(test.name)))
+		if err != nil {
+			t.Errorf("writeSourceMapFile(%q) did not write %s: %v", test.path, test.name, err)
+
+			continue
+		}
+
+		if string(content) != test.path {
+			t.Errorf("writeSourceMapFile(%q) wrote %q, want %q", test.path, content, test.path)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package xcrawl3r
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type PersistentStorage struct {
+	Path string
+
+	db   *bolt.DB
+	once sync.Once
+	err  error
+}
+
+func (s *PersistentStorage) Init() (err error) {
+	s.once.Do(func() {
+		directory := filepath.Dir(s.Path)
+
+		if directory != "" {
+			if _, s.err = os.Stat(directory); os.IsNotExist(s.err) {
+				if s.err = os.MkdirAll(directory, 0o750); s.err != nil {
+					return
+				}
+			}
+		}
+
+		s.db, s.err = bolt.Open(s.Path, 0o600, &bolt.Options{Timeout: time.Second})
+		if s.err != nil {
+			return
+		}
+
+		s.err = s.db.Update(func(tx *bolt.Tx) (err error) {
+			for _, bucket := range [][]byte{visitedBucket, frontierBucket} {
+				if _, err = tx.CreateBucketIfNotExists(bucket); err != nil {
+					return
+				}
+			}
+
+			return
+		})
+	})
+
+	err = s.err
+
+	return
+}
+
+func (s *PersistentStorage) Visited(requestID uint64) (err error) {
+	key := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(key, requestID)
+
+	err = s.db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(visitedBucket).Put(key, []byte{1})
+	})
+
+	return
+}
+
+func (s *PersistentStorage) IsVisited(requestID uint64) (visited bool, err error) {
+	key := make([]byte, 8)
+
+	binary.BigEndian.PutUint64(key, requestID)
+
+	err = s.db.View(func(tx *bolt.Tx) error {
+		visited = tx.Bucket(visitedBucket).Get(key) != nil
+
+		return nil
+	})
+
+	return
+}
+
+// NOTE: Cookies are kept by the crawler's own jar, saved to the state directory as they change,
+// the storage only implementing colly's interface.
+func (s *PersistentStorage) Cookies(_ *url.URL) (cookies string) {
+	return
+}
+
+func (s *PersistentStorage) SetCookies(_ *url.URL, _ string) {}
+
+func (s *PersistentStorage) Push(entry FrontierEntry) (err error) {
+	var value []byte
+
+	value, err = json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	err = s.db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(frontierBucket).Put(entry.key(), value)
+	})
+
+	return
+}
+
+func (s *PersistentStorage) Remove(entry FrontierEntry) (err error) {
+	err = s.db.Batch(func(tx *bolt.Tx) error {
+		return tx.Bucket(frontierBucket).Delete(entry.key())
+	})
+
+	return
+}
+
+func (s *PersistentStorage) Pending(target string) (entries []FrontierEntry, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(frontierBucket).ForEach(func(_, value []byte) (err error) {
+			var entry FrontierEntry
+
+			if err = json.Unmarshal(value, &entry); err != nil {
+				return
+			}
+
+			if entry.Target == target {
+				entries = append(entries, entry)
+			}
+
+			return
+		})
+	})
+
+	return
+}
+
+func (s *PersistentStorage) Close() (err error) {
+	if s.db == nil {
+		return
+	}
+
+	err = s.db.Close()
+
+	return
+}
+
+type FrontierEntry struct {
+	Target  string          `json:"target"`
+	URL     string          `json:"url"`
+	Source  ResultSource    `json:"source,omitempty"`
+	Result  *Result         `json:"result,omitempty"`
+	Request json.RawMessage `json:"request"`
+}
+
+func (e FrontierEntry) key() (key []byte) {
+	key = []byte(e.Target + "\x00" + e.URL)
+
+	return
+}
+
+type Frontier interface {
+	Push(entry FrontierEntry) (err error)
+	Remove(entry FrontierEntry) (err error)
+	Pending(target string) (entries []FrontierEntry, err error)
+}
+
+var (
+	visitedBucket  = []byte("visited")
+	frontierBucket = []byte("frontier")
+)
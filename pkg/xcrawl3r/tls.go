@@ -0,0 +1,229 @@
+package xcrawl3r
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+type TLS struct {
+	Certificates []Certificate
+	CA           string
+	Verify       bool
+	MinVersion   string
+	ServerName   string
+}
+
+type Certificate struct {
+	Host string
+	Cert string
+	Key  string
+}
+
+type hostTLSConfig struct {
+	rule   hostRule
+	config *tls.Config
+}
+
+type tlsTransport struct {
+	transport http.RoundTripper
+	hosts     []hostTransport
+}
+
+type hostTransport struct {
+	rule      hostRule
+	transport http.RoundTripper
+}
+
+// NOTE: Hosts with their own client certificate get their own transport, so connections
+// (and connection pools) never mix certificates.
+func (t *tlsTransport) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	transport := t.transport
+
+	if host, err := normalizeHost(req.URL.Hostname()); err == nil {
+		for _, h := range t.hosts {
+			if h.rule.Match(host) {
+				transport = h.transport
+
+				break
+			}
+		}
+	}
+
+	res, err = transport.RoundTrip(req)
+
+	return
+}
+
+func newTLSConfigs(cfg TLS) (config *tls.Config, hosts []hostTLSConfig, err error) {
+	config = &tls.Config{
+		InsecureSkipVerify: !cfg.Verify,
+		Renegotiation:      tls.RenegotiateOnceAsClient,
+	}
+
+	if cfg.MinVersion != "" {
+		version, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(cfg.MinVersion), "tls")]
+		if !ok {
+			err = fmt.Errorf("error parsing TLS version %s: %w", cfg.MinVersion, ErrInvalidTLSVersion)
+
+			return
+		}
+
+		config.MinVersion = version
+	}
+
+	if cfg.CA != "" {
+		var data []byte
+
+		data, err = os.ReadFile(cfg.CA)
+		if err != nil {
+			err = fmt.Errorf("error reading CA bundle %s: %w", cfg.CA, err)
+
+			return
+		}
+
+		pool, _ := x509.SystemCertPool()
+		if pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if ok := pool.AppendCertsFromPEM(data); !ok {
+			err = fmt.Errorf("error parsing CA bundle %s: %w", cfg.CA, ErrInvalidCABundle)
+
+			return
+		}
+
+		config.RootCAs = pool
+	}
+
+	for _, certificate := range cfg.Certificates {
+		key := certificate.Key
+
+		// NOTE: Without a key file, the key is expected in the certificate file.
+		if key == "" {
+			key = certificate.Cert
+		}
+
+		var pair tls.Certificate
+
+		pair, err = tls.LoadX509KeyPair(certificate.Cert, key)
+		if err != nil {
+			err = fmt.Errorf("error loading client certificate %s: %w", certificate.Cert, err)
+
+			return
+		}
+
+		host := strings.ToLower(strings.TrimSpace(certificate.Host))
+
+		if host == "" {
+			config.Certificates = []tls.Certificate{pair}
+
+			continue
+		}
+
+		var rule hostRule
+
+		rule, err = parseHostRule(host)
+		if err != nil {
+			err = fmt.Errorf("error parsing client certificate host %s: %w", host, err)
+
+			return
+		}
+
+		hostConfig := config.Clone()
+
+		hostConfig.Certificates = []tls.Certificate{pair}
+
+		hosts = append(hosts, hostTLSConfig{
+			rule:   rule,
+			config: hostConfig,
+		})
+	}
+
+	// NOTE: Host configurations are cloned as they are parsed, so they lack the default
+	// certificate if it is listed after them.
+	for _, host := range hosts {
+		if len(host.config.Certificates) == 0 {
+			host.config.Certificates = config.Certificates
+		}
+	}
+
+	return
+}
+
+// NOTE: The SNI override is sent to the target host only, never to the hosts it links to,
+// keeping the target's client certificate, if any.
+func newServerNameTLSConfig(config *tls.Config, hosts []hostTLSConfig, target, serverName string) (host hostTLSConfig, err error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return
+	}
+
+	name, err := normalizeHost(parsed.Hostname())
+	if err != nil {
+		return
+	}
+
+	host.rule, err = parseHostRule(name)
+	if err != nil {
+		return
+	}
+
+	host.config = config.Clone()
+
+	for _, h := range hosts {
+		if h.rule.Match(name) {
+			host.config = h.config.Clone()
+
+			break
+		}
+	}
+
+	host.config.ServerName = serverName
+
+	return
+}
+
+func isCertificateError(err error) (is bool) {
+	var (
+		verificationError        *tls.CertificateVerificationError
+		unknownAuthorityError    x509.UnknownAuthorityError
+		hostnameError            x509.HostnameError
+		certificateInvalidError  x509.CertificateInvalidError
+		systemRootsError         x509.SystemRootsError
+		constraintViolationError x509.ConstraintViolationError
+	)
+
+	is = errors.As(err, &verificationError) ||
+		errors.As(err, &unknownAuthorityError) ||
+		errors.As(err, &hostnameError) ||
+		errors.As(err, &certificateInvalidError) ||
+		errors.As(err, &systemRootsError) ||
+		errors.As(err, &constraintViolationError)
+
+	// NOTE: Alerts from servers rejecting our certificate are unexported, so are matched on their message.
+	if !is && err != nil {
+		message := err.Error()
+
+		is = strings.Contains(message, "remote error: tls: ") && strings.Contains(message, "certificate")
+	}
+
+	return
+}
+
+var (
+	tlsVersions = map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+
+	ErrInvalidTLSVersion = errors.New("invalid TLS version")
+	ErrInvalidCABundle   = errors.New("no certificates found in CA bundle")
+)
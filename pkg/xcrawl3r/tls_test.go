@@ -0,0 +1,406 @@
+package xcrawl3r
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewTLSConfigs(t *testing.T) {
+	directory := t.TempDir()
+
+	cert, key := writeCertificate(t, directory, "client")
+
+	CA := filepath.Join(directory, "invalid.pem")
+
+	if err := os.WriteFile(CA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  TLS
+		err  error
+	}{
+		{"defaults", TLS{}, nil},
+		{"min version", TLS{MinVersion: "TLS1.3"}, nil},
+		{"invalid min version", TLS{MinVersion: "1.4"}, ErrInvalidTLSVersion},
+		{"CA", TLS{CA: cert}, nil},
+		{"invalid CA", TLS{CA: CA}, ErrInvalidCABundle},
+		{"missing CA", TLS{CA: filepath.Join(directory, "missing.pem")}, os.ErrNotExist},
+		{"missing certificate", TLS{Certificates: []Certificate{{Cert: filepath.Join(directory, "missing.pem")}}}, os.ErrNotExist},
+		{"certificate", TLS{Certificates: []Certificate{{Cert: cert, Key: key}}}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := newTLSConfigs(test.cfg); !errors.Is(err, test.err) {
+				t.Errorf("newTLSConfigs() error = %v, want %v", err, test.err)
+			}
+		})
+	}
+
+	if _, _, err := newTLSConfigs(TLS{Certificates: []Certificate{{Host: "a..b", Cert: cert, Key: key}}}); err == nil {
+		t.Errorf("newTLSConfigs() with an invalid certificate host error = nil, want error")
+	}
+}
+
+func TestNewTLSConfigsOptions(t *testing.T) {
+	directory := t.TempDir()
+
+	cert, key := writeCertificate(t, directory, "ca")
+
+	config, hosts, err := newTLSConfigs(TLS{Verify: true, MinVersion: "1.2", CA: cert})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.InsecureSkipVerify || config.MinVersion != tls.VersionTLS12 || config.RootCAs == nil || len(hosts) != 0 {
+		t.Errorf("newTLSConfigs() = verify %t, min version %x, root CAs %t, %d hosts", !config.InsecureSkipVerify, config.MinVersion, config.RootCAs != nil, len(hosts))
+	}
+
+	config, _, err = newTLSConfigs(TLS{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !config.InsecureSkipVerify || config.MinVersion != 0 {
+		t.Errorf("newTLSConfigs() defaults = verify %t, min version %x, want no verification and the default version", !config.InsecureSkipVerify, config.MinVersion)
+	}
+
+	// NOTE: A certificate file holding both the certificate and its key needs no key file.
+	combined := filepath.Join(directory, "combined.pem")
+
+	certPEM, _ := os.ReadFile(cert)
+	keyPEM, _ := os.ReadFile(key)
+
+	if err := os.WriteFile(combined, append(certPEM, keyPEM...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := newTLSConfigs(TLS{Certificates: []Certificate{{Cert: combined}}}); err != nil {
+		t.Errorf("newTLSConfigs() with a combined certificate error = %v", err)
+	}
+}
+
+func TestNewTLSConfigsHostCertificates(t *testing.T) {
+	directory := t.TempDir()
+
+	defaultCert, defaultKey := writeCertificate(t, directory, "default")
+	hostCert, hostKey := writeCertificate(t, directory, "host")
+
+	config, hosts, err := newTLSConfigs(TLS{
+		Certificates: []Certificate{
+			{Host: "*.example.com", Cert: hostCert, Key: hostKey},
+			{Cert: defaultCert, Key: defaultKey},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(config.Certificates) != 1 || commonName(t, config.Certificates[0]) != "default" {
+		t.Fatalf("newTLSConfigs() default certificates = %d, want the default certificate", len(config.Certificates))
+	}
+
+	if len(hosts) != 1 || !hosts[0].rule.Match("api.example.com") || hosts[0].rule.Match("example.org") {
+		t.Fatalf("newTLSConfigs() hosts = %+v, want *.example.com", hosts)
+	}
+
+	if certificates := hosts[0].config.Certificates; len(certificates) != 1 || commonName(t, certificates[0]) != "host" {
+		t.Errorf("newTLSConfigs() host certificates = %d, want the host certificate", len(certificates))
+	}
+}
+
+func TestNewServerNameTLSConfig(t *testing.T) {
+	directory := t.TempDir()
+
+	defaultCert, defaultKey := writeCertificate(t, directory, "default")
+	hostCert, hostKey := writeCertificate(t, directory, "host")
+
+	config, hosts, err := newTLSConfigs(TLS{
+		Certificates: []Certificate{
+			{Host: "*.example.com", Cert: hostCert, Key: hostKey},
+			{Cert: defaultCert, Key: defaultKey},
+		},
+		ServerName: "origin.example.net",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.ServerName != "" {
+		t.Errorf("newTLSConfigs() server name = %q, want none", config.ServerName)
+	}
+
+	tests := []struct {
+		target      string
+		match       string
+		other       string
+		certificate string
+	}{
+		{"https://API.example.com:8443/path", "api.example.com", "www.example.com", "host"},
+		{"https://example.org/", "example.org", "www.example.org", "default"},
+		{"https://127.0.0.1:8443/", "127.0.0.1", "127.0.0.2", "default"},
+	}
+
+	for _, test := range tests {
+		host, err := newServerNameTLSConfig(config, hosts, test.target, "origin.example.net")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !host.rule.Match(test.match) || host.rule.Match(test.other) {
+			t.Errorf("newServerNameTLSConfig(%s) does not match the target host only", test.target)
+		}
+
+		if host.config.ServerName != "origin.example.net" {
+			t.Errorf("newServerNameTLSConfig(%s) server name = %q, want origin.example.net", test.target, host.config.ServerName)
+		}
+
+		if certificates := host.config.Certificates; len(certificates) != 1 || commonName(t, certificates[0]) != test.certificate {
+			t.Errorf("newServerNameTLSConfig(%s) certificates = %d, want the %s certificate", test.target, len(certificates), test.certificate)
+		}
+	}
+
+	if _, err := newServerNameTLSConfig(config, hosts, "https:///path", "origin.example.net"); err == nil {
+		t.Errorf("newServerNameTLSConfig() without a host error = nil, want error")
+	}
+}
+
+func TestCrawlerClientServerName(t *testing.T) {
+	serverNames := make(chan string, 2)
+
+	newServer := func() (server *httptest.Server) {
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		server.TLS = &tls.Config{
+			GetConfigForClient: func(hello *tls.ClientHelloInfo) (config *tls.Config, err error) {
+				serverNames <- hello.ServerName
+
+				return
+			},
+		}
+
+		server.StartTLS()
+
+		return
+	}
+
+	target := newServer()
+
+	defer target.Close()
+
+	other := newServer()
+
+	defer other.Close()
+
+	crawler, err := New(&Configuration{
+		Domains:        []string{"127.0.0.1"},
+		Timeout:        10,
+		StateDirectory: t.TempDir(),
+		TLS:            TLS{ServerName: "origin.example.net"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer crawler.Close()
+
+	client, err := crawler.client(nil, target.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := client.Get(target.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if serverName := <-serverNames; serverName != "origin.example.net" {
+		t.Errorf("server name sent to the target = %q, want origin.example.net", serverName)
+	}
+
+	// NOTE: Clients for other targets, or none, send no override.
+	client, err = crawler.client(nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = client.Get(other.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if serverName := <-serverNames; serverName != "" {
+		t.Errorf("server name sent without a target = %q, want none", serverName)
+	}
+}
+
+func TestTLSTransport(t *testing.T) {
+	transport := func(name string) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (res *http.Response, err error) {
+			res = &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"X-Transport": {name}},
+				Body:       http.NoBody,
+			}
+
+			return
+		})
+	}
+
+	rule, err := parseHostRule("*.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tlsTransport := &tlsTransport{
+		transport: transport("default"),
+		hosts: []hostTransport{
+			{rule: rule, transport: transport("host")},
+		},
+	}
+
+	tests := []struct {
+		URL       string
+		transport string
+	}{
+		{"https://api.example.com/", "host"},
+		{"https://API.EXAMPLE.COM:8443/", "host"},
+		{"https://example.org/", "default"},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(http.MethodGet, test.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := tlsTransport.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if transport := res.Header.Get("X-Transport"); transport != test.transport {
+			t.Errorf("RoundTrip(%s) used the %s transport, want %s", test.URL, transport, test.transport)
+		}
+	}
+}
+
+func TestIsCertificateError(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// NOTE: The server logs the handshakes the client aborts.
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+
+	server.StartTLS()
+
+	defer server.Close()
+
+	config, _, err := newTLSConfigs(TLS{Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: config,
+		},
+	}
+
+	_, err = client.Get(server.URL)
+
+	tests := []struct {
+		name string
+		err  error
+		is   bool
+	}{
+		{"unknown authority", err, true},
+		{"hostname", x509.HostnameError{Certificate: &x509.Certificate{}, Host: "example.com"}, true},
+		{"rejected", errors.New("remote error: tls: bad certificate"), true},
+		{"other alert", errors.New("remote error: tls: protocol version not supported"), false},
+		{"other", errors.New("connection refused"), false},
+		{"nil", nil, false},
+	}
+
+	for _, test := range tests {
+		if is := isCertificateError(test.err); is != test.is {
+			t.Errorf("isCertificateError(%s: %v) = %t, want %t", test.name, test.err, is, test.is)
+		}
+	}
+}
+
+func writeCertificate(t *testing.T, directory, name string) (cert, key string) {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &private.PublicKey, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert = filepath.Join(directory, name+".crt")
+	key = filepath.Join(directory, name+".key")
+
+	if err := os.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func commonName(t *testing.T, certificate tls.Certificate) (name string) {
+	t.Helper()
+
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name = parsed.Subject.CommonName
+
+	return
+}
@@ -1,19 +1,28 @@
 package xcrawl3r
 
 import (
+	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"path"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gocolly/colly/v2"
 	"github.com/gocolly/colly/v2/debug"
 	"github.com/gocolly/colly/v2/extensions"
-	"github.com/gocolly/colly/v2/proxy"
 	"github.com/gocolly/colly/v2/storage"
 	hqgourlextractor "github.com/hueristiq/hq-go-url/extractor"
 	hqgourlparser "github.com/hueristiq/hq-go-url/parser"
@@ -22,41 +31,137 @@ import (
 type Crawler struct {
 	cfg *Configuration
 
-	_URLFilterRegex    *regexp.Regexp
+	headers     http.Header
+	hostHeaders []hostHeader
+
 	_URLExtractorRegex *regexp.Regexp
 
 	fileURLsToRequestExtRegex    *regexp.Regexp
 	fileURLsNotToRequextExtRegex *regexp.Regexp
 
-	storage *storage.InMemoryStorage
+	scope *scope
+
+	tlsConfig      *tls.Config
+	hostTLSConfigs []hostTLSConfig
+
+	resolves map[string][]string
+	resolver *net.Resolver
+
+	proxies     map[string]*proxyEntry
+	proxyPool   *proxyPool
+	proxyRoutes []proxyRoute
+
+	routeLookups routeLookups
+
+	har *harWriter
+
+	archive *archive
+
+	limiter *limiter
+
+	jar *cookieJar
+
+	storage storage.Storage
 }
 
 func (c *Crawler) Crawl(target string) <-chan Result {
+	return c.CrawlContext(context.Background(), target)
+}
+
+func (c *Crawler) CrawlContext(ctx context.Context, target string) <-chan Result {
 	results := make(chan Result)
 
 	go func() {
 		defer close(results)
 
-		targets, err := c.targets(target)
+		publish := func(result Result) {
+			select {
+			case results <- result:
+			case <-ctx.Done():
+			}
+		}
+
+		seeds, err := c.targets(target)
 		if err != nil {
 			result := Result{
 				Type:  ResultError,
 				Error: fmt.Errorf("error creating targets for %s: %w", target, err),
 			}
 
-			results <- result
+			publish(result)
+
+			return
+		}
+
+		store := c.storage
+
+		if store == nil {
+			store, err = c.newStorage(target)
+			if err != nil {
+				result := Result{
+					Type:  ResultError,
+					Error: fmt.Errorf("error creating storage for %s: %w", target, err),
+				}
+
+				publish(result)
+
+				return
+			}
+
+			defer func() {
+				if closer, ok := store.(io.Closer); ok {
+					if err := closer.Close(); err != nil {
+						result := Result{
+							Type:  ResultError,
+							Error: fmt.Errorf("error closing storage for %s: %w", target, err),
+						}
+
+						publish(result)
+					}
+				}
+			}()
+		}
+
+		var session *session
+
+		if c.cfg.Login != nil && len(c.cfg.Login.Steps) > 0 && c.archive == nil {
+			session, err = c.newSession(seeds[0].URL)
+			if err == nil {
+				err = session.Authenticate(ctx, 0)
+			}
+
+			if err != nil {
+				result := Result{
+					Type:  ResultError,
+					Error: fmt.Errorf("error logging in to %s: %w", target, err),
+				}
+
+				publish(result)
+
+				return
+			}
+		}
+
+		client, err := c.client(session, seeds[0].URL)
+		if err != nil {
+			result := Result{
+				Type:  ResultError,
+				Error: fmt.Errorf("error creating client for %s: %w", target, err),
+			}
+
+			publish(result)
 
 			return
 		}
 
-		collector, err := c.collector()
+		collector, err := c.collector(ctx, store, client, session)
 		if err != nil {
 			result := Result{
 				Type:  ResultError,
 				Error: fmt.Errorf("error creating collector for %s: %w", target, err),
 			}
 
-			results <- result
+			publish(result)
 
 			return
 		}
@@ -65,15 +170,344 @@ func (c *Crawler) Crawl(target string) <-chan Result {
 		isURLToFileContextTrueValue := "true"
 		isURLToFileContextFalseValue := "false"
 
+		resultContextKey := "resultContextKey"
+		sourceContextKey := "sourceContextKey"
+		frontierContextKey := "frontierContextKey"
+		attemptsContextKey := "attemptsContextKey"
+		generationContextKey := "generationContextKey"
+		reauthenticatedContextKey := "reauthenticatedContextKey"
+
+		// NOTE: The key extensions.Referer reads the Referer header from.
+		refererContextKey := "_referer"
+
+		frontier, persistent := store.(Frontier)
+
+		var policy *robots
+
+		if c.cfg.RespectRobots {
+			policy = newRobots(client, c.headers)
+		}
+
+		settle := func(ctx *colly.Context) {
+			if !persistent {
+				return
+			}
+
+			entry, ok := ctx.GetAny(frontierContextKey).(FrontierEntry)
+			if !ok {
+				return
+			}
+
+			if err := frontier.Remove(entry); err != nil {
+				result := Result{
+					Type:  ResultError,
+					Error: fmt.Errorf("error removing %s from frontier: %w", entry.URL, err),
+				}
+
+				publish(result)
+			}
+		}
+
+		attempts := func(ctx *colly.Context) (attempt int) {
+			attempt, ok := ctx.GetAny(attemptsContextKey).(int)
+			if !ok {
+				attempt = 1
+			}
+
+			return
+		}
+
+		// NOTE: A logged out request re-authenticates, unless another request already did, and is retried once.
+		reauthenticate := func(response *colly.Response) (retried bool) {
+			if _, ok := response.Ctx.GetAny(reauthenticatedContextKey).(bool); ok {
+				return
+			}
+
+			generation, _ := response.Ctx.GetAny(generationContextKey).(int)
+
+			if err := session.Authenticate(ctx, generation); err != nil {
+				result := Result{
+					Type:  ResultError,
+					Error: fmt.Errorf("error re-authenticating to %s: %w", target, err),
+				}
+
+				publish(result)
+
+				return
+			}
+
+			response.Ctx.Put(reauthenticatedContextKey, true)
+
+			retried = response.Request.Retry() == nil
+
+			return
+		}
+
+		// NOTE: Results count the target at depth 0, where colly counts seeds at depth 1.
+		depthOf := func(request *colly.Request) (depth int) {
+			depth = request.Depth - 1
+
+			return
+		}
+
+		pending := func(ctx *colly.Context) (result *Result, ok bool) {
+			result, ok = ctx.GetAny(resultContextKey).(*Result)
+
+			return
+		}
+
+		visit := func(parent *colly.Request, URL string, depth int, source ResultSource, result *Result) (err error) {
+			var request *colly.Request
+
+			request, err = parent.New(http.MethodGet, URL, nil)
+			if err != nil {
+				return
+			}
+
+			request.Ctx = colly.NewContext()
+			request.Ctx.Put(refererContextKey, parent.URL.String())
+			request.Depth = depth + 1
+
+			if source != "" {
+				request.Ctx.Put(sourceContextKey, source)
+			}
+
+			if result != nil {
+				request.Ctx.Put(resultContextKey, result)
+			}
+
+			err = request.Do()
+
+			return
+		}
+
+		inventory := newParameters()
+		hostInventory := newParameters()
+
+		collect := func(u *url.URL, names []string, source ResultSource, referer string, depth int) {
+			endpoint := parameterEndpoint(u)
+			host := parameterHost(u)
+
+			for _, name := range names {
+				if added := inventory.Add(endpoint, name); !added {
+					continue
+				}
+
+				result := Result{
+					Type:      ResultParameter,
+					Value:     endpoint,
+					Source:    source,
+					Referer:   referer,
+					Depth:     depth,
+					Parameter: name,
+				}
+
+				publish(result)
+
+				if added := hostInventory.Add(host, name); !added {
+					continue
+				}
+
+				result.Type = ResultHostParameter
+				result.Value = host
+
+				publish(result)
+			}
+		}
+
+		var outOfScope, beyondDepth sync.Map
+
+		var dedupe *patterns
+
+		if c.cfg.PatternDedupe {
+			dedupe = newPatterns(c.cfg.PatternVariants)
+		}
+
+		discover := func(parent *colly.Request, result Result, source ResultSource) {
+			if canonical, err := canonicalize(result.Value, c.cfg.PatternDedupe); err == nil {
+				result.Value = canonical
+			}
+
+			if valid := c.validate(result.Value); !valid {
+				if parsed, err := url.Parse(result.Value); !c.cfg.ReportOutOfScope || err != nil || parsed.Host == "" {
+					return
+				}
+
+				if _, loaded := outOfScope.LoadOrStore(result.Value, struct{}{}); loaded {
+					return
+				}
+
+				result.Type = ResultOutOfScope
+				result.Referer = parent.URL.String()
+
+				publish(result)
+
+				return
+			}
+
+			if dedupe != nil {
+				if admitted := dedupe.Admit(result.Value); !admitted {
+					return
+				}
+			}
+
+			if u, err := url.Parse(result.Value); err == nil {
+				collect(u, queryParameterNames(u), result.Source, parent.URL.String(), result.Depth)
+			}
+
+			result.Type = ResultURL
+			result.Referer = parent.URL.String()
+
+			if err := visit(parent, result.Value, result.Depth, source, &result); err != nil {
+				// NOTE: URLs found again, after being visited, are reported once, when first found.
+				if isAlreadyVisited(err) {
+					return
+				}
+
+				// NOTE: URLs beyond the maximum depth are held, to be reported unless visited at a lower one.
+				if errors.Is(err, colly.ErrMaxDepth) {
+					beyondDepth.LoadOrStore(result.Value, result)
+
+					return
+				}
+
+				publish(result)
+
+				result := Result{
+					Type:  ResultError,
+					Error: fmt.Errorf("error visiting %s: %w", result.Value, err),
+				}
+
+				publish(result)
+			}
+		}
+
+		reconstruct := func(request *colly.Request, m *sourceMap) {
+			seen := map[string]struct{}{}
+
+			for _, file := range m.Files() {
+				result := Result{
+					Type:    ResultFile,
+					Value:   file.Path,
+					Source:  ResultSourceSourceMap,
+					Referer: request.URL.String(),
+					Depth:   depthOf(request),
+				}
+
+				publish(result)
+
+				if file.Content == "" {
+					continue
+				}
+
+				if c.cfg.SourceMapsDirectory != "" {
+					if err := writeSourceMapFile(c.cfg.SourceMapsDirectory, request.URL.Host, file); err != nil {
+						result := Result{
+							Type:  ResultError,
+							Error: fmt.Errorf("error writing %s source from %s: %w", file.Path, request.URL.String(), err),
+						}
+
+						publish(result)
+					}
+				}
+
+				for _, endpoint := range extractJavaScriptEndpoints(file.Content) {
+					URL := resolveJavaScriptEndpoint(request.URL, endpoint)
+
+					if _, ok := seen[URL]; ok || URL == "" {
+						continue
+					}
+
+					seen[URL] = struct{}{}
+
+					result := Result{
+						Value:  URL,
+						Source: ResultSourceSourceMap,
+						Depth:  depthOf(request) + 1,
+					}
+
+					discover(request, result, "")
+				}
+			}
+		}
+
 		collector.OnRequest(func(request *colly.Request) {
 			ext := path.Ext(request.URL.Path)
 
 			if match := c.fileURLsNotToRequextExtRegex.MatchString(ext); match {
 				request.Abort()
 
+				if ctx.Err() != nil {
+					return
+				}
+
+				if result, ok := pending(request.Ctx); ok {
+					publish(*result)
+				}
+
+				return
+			}
+
+			if persistent {
+				if _, ok := request.Ctx.GetAny(frontierContextKey).(FrontierEntry); !ok {
+					entry := FrontierEntry{
+						Target: target,
+						URL:    request.URL.String(),
+					}
+
+					entry.Source, _ = request.Ctx.GetAny(sourceContextKey).(ResultSource)
+					entry.Result, _ = pending(request.Ctx)
+
+					var err error
+
+					entry.Request, err = request.Marshal()
+					if err == nil {
+						err = frontier.Push(entry)
+					}
+
+					if err != nil {
+						result := Result{
+							Type:  ResultError,
+							Error: fmt.Errorf("error adding %s to frontier: %w", entry.URL, err),
+						}
+
+						publish(result)
+					}
+
+					request.Ctx.Put(frontierContextKey, entry)
+				}
+			}
+
+			if ctx.Err() != nil {
+				request.Abort()
+
 				return
 			}
 
+			if policy != nil && request.URL.Path != "/robots.txt" {
+				if allowed := policy.Allowed(ctx, request.URL); !allowed {
+					request.Abort()
+
+					if result, ok := pending(request.Ctx); ok {
+						publish(*result)
+					}
+
+					settle(request.Ctx)
+
+					return
+				}
+
+				if err := policy.Wait(ctx, request.URL); err != nil {
+					request.Abort()
+
+					return
+				}
+			}
+
+			if session != nil {
+				request.Ctx.Put(generationContextKey, session.Generation())
+			}
+
 			request.Ctx.Put(isURLToFileContextKey, isURLToFileContextFalseValue)
 
 			if match := c.fileURLsToRequestExtRegex.MatchString(ext); match {
@@ -82,21 +516,201 @@ func (c *Crawler) Crawl(target string) <-chan Result {
 		})
 
 		collector.OnError(func(response *colly.Response, err error) {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if session != nil && session.LoggedOut(err, response.Body) {
+				if retried := reauthenticate(response); retried {
+					return
+				}
+			}
+
+			attempt := attempts(response.Ctx)
+
+			if attempt < c.cfg.Retry.Attempts && c.cfg.Retry.Retryable(response.StatusCode, err) {
+				if err := sleep(ctx, c.cfg.Retry.Wait(attempt)); err != nil {
+					return
+				}
+
+				response.Ctx.Put(attemptsContextKey, attempt+1)
+
+				if err := response.Request.Retry(); err == nil {
+					return
+				}
+			}
+
+			settle(response.Ctx)
+
+			if result, ok := pending(response.Ctx); ok {
+				if response.StatusCode > 0 {
+					result.StatusCode = response.StatusCode
+					result.ContentType = response.Headers.Get("Content-Type")
+					result.ContentLength = int64(len(response.Body))
+				}
+
+				result.Attempts = attempt
+
+				publish(*result)
+			}
+
 			result := Result{
-				Type:  ResultError,
-				Error: fmt.Errorf("error requesting %s: %w", response.Request.URL.String(), err),
+				Type:       ResultError,
+				Attempts:   attempt,
+				ErrorClass: ErrorClass(err),
+				Error:      fmt.Errorf("error requesting %s (attempt %d): %w", response.Request.URL.String(), attempt, err),
 			}
 
-			results <- result
+			publish(result)
 		})
 
 		collector.OnResponse(func(response *colly.Response) {
+			if session != nil && session.LoggedOut(nil, response.Body) {
+				if retried := reauthenticate(response); retried {
+					return
+				}
+			}
+
+			if result, ok := pending(response.Ctx); ok {
+				result.Attempts = attempts(response.Ctx)
+				result.StatusCode = response.StatusCode
+				result.ContentType = response.Headers.Get("Content-Type")
+				result.ContentLength = int64(len(response.Body))
+
+				publish(*result)
+			}
+
+			source := ResultSourceBody
+
+			if s, ok := response.Ctx.GetAny(sourceContextKey).(ResultSource); ok {
+				source = s
+			}
+
+			collect(response.Request.URL, queryParameterNames(response.Request.URL), source, response.Request.Headers.Get("Referer"), depthOf(response.Request))
+
+			if source == ResultSourceSitemap {
+				if entries, _ := parseSitemap(response.Body); len(entries) > 0 {
+					for _, entry := range entries {
+						result := Result{
+							Value:        response.Request.AbsoluteURL(entry.URL),
+							Source:       ResultSourceSitemap,
+							Depth:        depthOf(response.Request) + 1,
+							Tag:          entry.Tag,
+							Attribute:    entry.Attribute,
+							LastModified: entry.LastModified,
+						}
+
+						if entry.Index {
+							result.Depth = depthOf(response.Request)
+
+							discover(response.Request, result, ResultSourceSitemap)
+
+							continue
+						}
+
+						discover(response.Request, result, "")
+					}
+
+					return
+				}
+			}
+
+			if source == ResultSourceRobots && response.Request.URL.Path == "/robots.txt" {
+				for _, directive := range parseRobotsDirectives(response.Body) {
+					result := Result{
+						Value:  response.Request.AbsoluteURL(directive.Value),
+						Source: ResultSourceRobots,
+						Depth:  depthOf(response.Request),
+						Tag:    directive.Name,
+					}
+
+					switch directive.Name {
+					case robotsDirectiveSitemap:
+						discover(response.Request, result, ResultSourceSitemap)
+					default:
+						discover(response.Request, result, "")
+					}
+				}
+
+				return
+			}
+
+			if source == ResultSourceSourceMap || isSourceMap(response.Request.URL) {
+				if m, err := parseSourceMap(response.Body); err == nil {
+					reconstruct(response.Request, m)
+
+					return
+				}
+
+				// NOTE: Guessed source maps, not linked to by their script, that do not parse are most
+				// often a catch-all page, not worth scanning for URLs.
+				if _, ok := pending(response.Ctx); !ok && source == ResultSourceSourceMap {
+					return
+				}
+			}
+
+			if isJSON(response.Request.URL, response.Headers.Get("Content-Type")) {
+				collect(response.Request.URL, jsonParameterNames(response.Body), ResultSourceJSON, "", depthOf(response.Request))
+			}
+
 			if response.Ctx.Get(isURLToFileContextKey) == isURLToFileContextFalseValue {
 				return
 			}
 
 			body := string(response.Body)
 
+			seen := map[string]struct{}{}
+
+			if isJavaScript(response.Request.URL, response.Headers.Get("Content-Type")) {
+				collect(response.Request.URL, javaScriptParameterNames(body), ResultSourceJS, "", depthOf(response.Request))
+
+				sourceMapURL := sourceMappingURL(body)
+
+				for _, header := range []string{"SourceMap", "X-SourceMap"} {
+					if value := response.Headers.Get(header); value != "" {
+						sourceMapURL = value
+					}
+				}
+
+				switch {
+				case sourceMapURL == "":
+				case strings.HasPrefix(sourceMapURL, "data:"):
+					if data, ok := decodeInlineSourceMap(sourceMapURL); ok {
+						if m, err := parseSourceMap(data); err == nil {
+							reconstruct(response.Request, m)
+						}
+					}
+				default:
+					result := Result{
+						Value:  response.Request.AbsoluteURL(sourceMapURL),
+						Source: ResultSourceSourceMap,
+						Depth:  depthOf(response.Request) + 1,
+					}
+
+					seen[result.Value] = struct{}{}
+
+					discover(response.Request, result, ResultSourceSourceMap)
+				}
+
+				for _, endpoint := range extractJavaScriptEndpoints(body) {
+					URL := resolveJavaScriptEndpoint(response.Request.URL, endpoint)
+
+					if _, ok := seen[URL]; ok || URL == "" {
+						continue
+					}
+
+					seen[URL] = struct{}{}
+
+					result := Result{
+						Value:  URL,
+						Source: ResultSourceJS,
+						Depth:  depthOf(response.Request) + 1,
+					}
+
+					discover(response.Request, result, "")
+				}
+			}
+
 			replacer := strings.NewReplacer(
 				"*", "",
 				`\u002f`, "/",
@@ -110,25 +724,44 @@ func (c *Crawler) Crawl(target string) <-chan Result {
 			for _, link := range links {
 				URL := response.Request.AbsoluteURL(link)
 
-				if valid := c.validate(URL); !valid {
+				if _, ok := seen[URL]; ok {
 					continue
 				}
 
+				seen[URL] = struct{}{}
+
 				result := Result{
-					Type:  ResultURL,
-					Value: URL,
+					Value:  URL,
+					Source: source,
+					Depth:  depthOf(response.Request) + 1,
 				}
 
-				results <- result
+				discover(response.Request, result, "")
+			}
+		})
 
-				if err := response.Request.Visit(URL); err != nil {
-					result := Result{
-						Type:  ResultError,
-						Error: fmt.Errorf("error visiting %s: %w", URL, err),
-					}
+		collector.OnHTML("script:not([src])", func(e *colly.HTMLElement) {
+			if e.Request.Ctx.Get(isURLToFileContextKey) == isURLToFileContextTrueValue {
+				return
+			}
 
-					results <- result
+			collect(e.Request.URL, javaScriptParameterNames(e.Text), ResultSourceJS, "", depthOf(e.Request))
+
+			for _, endpoint := range extractJavaScriptEndpoints(e.Text) {
+				URL := resolveJavaScriptEndpoint(e.Request.URL, endpoint)
+
+				if URL == "" {
+					continue
 				}
+
+				result := Result{
+					Value:  URL,
+					Source: ResultSourceJS,
+					Depth:  depthOf(e.Request) + 1,
+					Tag:    e.Name,
+				}
+
+				discover(e.Request, result, "")
 			}
 		})
 
@@ -139,27 +772,84 @@ func (c *Crawler) Crawl(target string) <-chan Result {
 
 			link := e.Attr("href")
 
-			URL := e.Request.AbsoluteURL(link)
+			result := Result{
+				Value:     e.Request.AbsoluteURL(link),
+				Source:    ResultSourceHref,
+				Depth:     depthOf(e.Request) + 1,
+				Tag:       e.Name,
+				Attribute: "href",
+			}
+
+			discover(e.Request, result, "")
+		})
+
+		var forms sync.Map
 
-			if valid := c.validate(URL); !valid {
+		collector.OnHTML("form", func(e *colly.HTMLElement) {
+			if e.Request.Ctx.Get(isURLToFileContextKey) == isURLToFileContextTrueValue {
+				return
+			}
+
+			action := e.Request.AbsoluteURL(e.Attr("action"))
+
+			if canonical, err := canonicalize(action, c.cfg.PatternDedupe); err == nil {
+				action = canonical
+			}
+
+			if valid := c.validate(action); !valid {
+				return
+			}
+
+			form := parseForm(e)
+
+			if _, loaded := forms.LoadOrStore(form.key(action), struct{}{}); loaded {
 				return
 			}
 
 			result := Result{
-				Type:  ResultURL,
-				Value: URL,
+				Type:      ResultForm,
+				Value:     action,
+				Source:    ResultSourceForm,
+				Referer:   e.Request.URL.String(),
+				Depth:     depthOf(e.Request) + 1,
+				Tag:       e.Name,
+				Attribute: "action",
+				Form:      form,
 			}
 
-			results <- result
+			publish(result)
 
-			if err := e.Request.Visit(URL); err != nil {
-				result := Result{
-					Type:  ResultError,
-					Error: fmt.Errorf("error visiting %s: %w", URL, err),
+			if u, err := url.Parse(action); err == nil {
+				names := []string{}
+
+				for _, field := range form.Fields {
+					names = append(names, field.Name)
 				}
 
-				results <- result
+				collect(u, names, ResultSourceForm, e.Request.URL.String(), result.Depth)
 			}
+
+			if !c.cfg.SubmitForms || form.Method != http.MethodGet {
+				return
+			}
+
+			URL, err := url.Parse(action)
+			if err != nil {
+				return
+			}
+
+			URL.RawQuery = form.Query().Encode()
+			URL.Fragment = ""
+
+			result = Result{
+				Value:     URL.String(),
+				Source:    ResultSourceForm,
+				Depth:     depthOf(e.Request) + 1,
+				Tag:       e.Name,
+				Attribute: "action",
+			}
+
+			discover(e.Request, result, "")
 		})
 
 		collector.OnHTML("[src]", func(e *colly.HTMLElement) {
@@ -169,61 +859,200 @@ func (c *Crawler) Crawl(target string) <-chan Result {
 
 			link := e.Attr("src")
 
-			URL := e.Request.AbsoluteURL(link)
+			URL := e.Request.AbsoluteURL(link)
+
+			result := Result{
+				Value:     URL,
+				Source:    ResultSourceSrc,
+				Depth:     depthOf(e.Request) + 1,
+				Tag:       e.Name,
+				Attribute: "src",
+			}
+
+			discover(e.Request, result, "")
+
+			if !c.validate(URL) {
+				return
+			}
+
+			if variant, ok := sourceMapVariant(URL); ok && c.validate(variant) {
+				if err := visit(e.Request, variant, depthOf(e.Request)+1, ResultSourceSourceMap, nil); err != nil && !isAlreadyVisited(err) && !errors.Is(err, colly.ErrMaxDepth) {
+					result := Result{
+						Type:  ResultError,
+						Error: fmt.Errorf("error visiting %s: %w", variant, err),
+					}
+
+					publish(result)
+				}
+			}
+
+			if strings.Contains(URL, ".min.") {
+				URL = strings.ReplaceAll(URL, ".min.", ".")
+
+				if !c.validate(URL) {
+					return
+				}
+
+				if err := visit(e.Request, URL, depthOf(e.Request)+1, "", nil); err != nil && !isAlreadyVisited(err) && !errors.Is(err, colly.ErrMaxDepth) {
+					result := Result{
+						Type:  ResultError,
+						Error: fmt.Errorf("error visiting %s: %w", URL, err),
+					}
+
+					publish(result)
+				}
+			}
+		})
+
+		collector.OnScraped(func(response *colly.Response) {
+			settle(response.Ctx)
+		})
+
+		if persistent {
+			entries, err := frontier.Pending(target)
+			if err != nil {
+				result := Result{
+					Type:  ResultError,
+					Error: fmt.Errorf("error loading frontier for %s: %w", target, err),
+				}
+
+				publish(result)
+			}
+
+			for _, entry := range entries {
+				if ctx.Err() != nil {
+					break
+				}
+
+				request, err := collector.UnmarshalRequest(entry.Request)
+				if err != nil {
+					result := Result{
+						Type:  ResultError,
+						Error: fmt.Errorf("error restoring %s from frontier: %w", entry.URL, err),
+					}
+
+					publish(result)
+
+					continue
+				}
+
+				request.Ctx = colly.NewContext()
+
+				if entry.Source != "" {
+					request.Ctx.Put(sourceContextKey, entry.Source)
+				}
+
+				if entry.Result != nil {
+					request.Ctx.Put(resultContextKey, entry.Result)
+				}
+
+				// NOTE: Retry skips the visited check, frontier entries are already marked as visited.
+				if err := request.Retry(); err != nil {
+					result := Result{
+						Type:  ResultError,
+						Error: fmt.Errorf("error visiting %s: %w", entry.URL, err),
+					}
+
+					publish(result)
+				}
+			}
+		}
+
+		for i, seed := range seeds {
+			if ctx.Err() != nil {
+				break
+			}
+
+			if !c.validate(seed.URL) {
+				if i == 0 {
+					result := Result{
+						Type:  ResultError,
+						Error: fmt.Errorf("error visiting %s: %w", seed.URL, ErrOutOfScope),
+					}
+
+					publish(result)
+				}
 
-			if valid := c.validate(URL); !valid {
-				return
+				continue
 			}
 
-			result := Result{
-				Type:  ResultURL,
-				Value: URL,
+			seedCtx := colly.NewContext()
+
+			if seed.Source != "" {
+				seedCtx.Put(sourceContextKey, seed.Source)
 			}
 
-			results <- result
+			if err := collector.Request(http.MethodGet, seed.URL, nil, seedCtx, nil); err != nil {
+				if isAlreadyVisited(err) {
+					continue
+				}
 
-			if err := e.Request.Visit(URL); err != nil {
 				result := Result{
 					Type:  ResultError,
-					Error: fmt.Errorf("error visiting %s: %w", URL, err),
+					Error: fmt.Errorf("error visiting %s: %w", seed.URL, err),
 				}
 
-				results <- result
+				publish(result)
 			}
+		}
 
-			if strings.Contains(URL, ".min.") {
-				URL = strings.ReplaceAll(URL, ".min.", ".")
+		collector.Wait()
 
-				if err := e.Request.Visit(URL); err != nil {
-					result := Result{
-						Type:  ResultError,
-						Error: fmt.Errorf("error visiting %s: %w", URL, err),
-					}
+		// NOTE: Replaying, archived responses of the target's host not reached by links are processed
+		// once the crawl settles, so that linked ones are reported with their referer.
+		for _, URL := range c.archived(seeds[0].URL) {
+			if ctx.Err() != nil {
+				break
+			}
 
-					results <- result
-				}
+			if !c.validate(URL) {
+				continue
 			}
-		})
 
-		for _, target = range targets {
-			if err := collector.Visit(target); err != nil {
+			result := &Result{
+				Type:   ResultURL,
+				Value:  URL,
+				Source: ResultSourceArchive,
+			}
+
+			archivedCtx := colly.NewContext()
+
+			archivedCtx.Put(resultContextKey, result)
+
+			if err := collector.Request(http.MethodGet, URL, nil, archivedCtx, nil); err != nil {
+				if isAlreadyVisited(err) {
+					continue
+				}
+
+				publish(*result)
+
 				result := Result{
 					Type:  ResultError,
-					Error: fmt.Errorf("error visiting %s: %w", target, err),
+					Error: fmt.Errorf("error visiting %s: %w", URL, err),
 				}
 
-				results <- result
+				publish(result)
 			}
 		}
 
 		collector.Wait()
+
+		beyondDepth.Range(func(key, value any) bool {
+			if visited, err := collector.HasVisited(key.(string)); err == nil && visited {
+				return true
+			}
+
+			publish(value.(Result))
+
+			return ctx.Err() == nil
+		})
 	}()
 
 	return results
 }
 
-func (c *Crawler) targets(target string) (targets []string, err error) {
-	targets = []string{}
+func (c *Crawler) targets(target string) (seeds []seed, err error) {
+	seeds = []seed{}
 
 	var parsedTargetURL *hqgourlparser.URL
 
@@ -232,45 +1061,43 @@ func (c *Crawler) targets(target string) (targets []string, err error) {
 		return
 	}
 
-	targets = append(targets, parsedTargetURL.String())
+	if canonical, err := canonicalize(parsedTargetURL.String(), c.cfg.PatternDedupe); err == nil {
+		if u, err := url.Parse(canonical); err == nil {
+			parsedTargetURL.URL = u
+		}
+	}
+
+	targetURL := parsedTargetURL.String()
 
-	if strings.Contains(parsedTargetURL.String(), ".min.") {
-		targets = append(targets, strings.ReplaceAll(parsedTargetURL.String(), ".min.", "."))
+	seeds = append(seeds, seed{URL: targetURL})
+
+	if strings.Contains(targetURL, ".min.") {
+		seeds = append(seeds, seed{URL: strings.ReplaceAll(targetURL, ".min.", ".")})
 	}
 
 	robotsTXTURL := fmt.Sprintf("%s://%s/robots.txt", parsedTargetURL.Scheme, parsedTargetURL.Host)
 
-	targets = append(targets, robotsTXTURL)
+	seeds = append(seeds, seed{URL: robotsTXTURL, Source: ResultSourceRobots})
 
-	sitemaps := []string{
-		"/sitemap.xml",
-		"/sitemap_news.xml",
-		"/sitemap_index.xml",
-		"/sitemap-index.xml",
-		"/sitemapindex.xml",
-		"/sitemap-news.xml",
-		"/post-sitemap.xml",
-		"/page-sitemap.xml",
-		"/portfolio-sitemap.xml",
-		"/home_slider-sitemap.xml",
-		"/category-sitemap.xml",
-		"/author-sitemap.xml",
-	}
+	for _, sitemap := range c.cfg.SitemapPaths {
+		if !strings.HasPrefix(sitemap, "/") {
+			sitemap = "/" + sitemap
+		}
 
-	for _, sitemap := range sitemaps {
 		sitemapURL := fmt.Sprintf("%s://%s%s", parsedTargetURL.Scheme, parsedTargetURL.Host, sitemap)
 
-		targets = append(targets, sitemapURL)
+		seeds = append(seeds, seed{URL: sitemapURL, Source: ResultSourceSitemap})
 	}
 
 	return
 }
 
-func (c *Crawler) collector() (collector *colly.Collector, err error) {
+func (c *Crawler) collector(ctx context.Context, store storage.Storage, client *http.Client, session *session) (collector *colly.Collector, err error) {
 	collector = colly.NewCollector(
+		colly.StdlibContext(ctx),
 		colly.Async(true),
+		// NOTE: robots.txt compliance, when enabled, is enforced by the crawler itself.
 		colly.IgnoreRobotsTxt(),
-		colly.URLFilters(c._URLFilterRegex),
 		colly.MaxDepth(c.cfg.Depth),
 	)
 
@@ -282,97 +1109,373 @@ func (c *Crawler) collector() (collector *colly.Collector, err error) {
 		return
 	}
 
-	if len(c.cfg.Headers) > 0 {
+	if len(c.headers) > 0 {
 		collector.OnRequest(func(request *colly.Request) {
-			for _, entry := range c.cfg.Headers {
-				var splitEntry []string
+			for header := range c.headers {
+				request.Headers.Set(header, c.headers.Get(header))
+			}
+		})
+	}
 
-				switch {
-				case strings.Contains(entry, ": "):
-					splitEntry = strings.SplitN(entry, ": ", 2)
-				case strings.Contains(entry, ":"):
-					splitEntry = strings.SplitN(entry, ":", 2)
-				default:
-					continue
-				}
+	extensions.Referer(collector)
+
+	// NOTE: Must come BEFORE .SetStorage calls
+	collector.SetClient(client)
+
+	// NOTE: Must come AFTER .SetClient calls, which replace the client along with its redirect policy.
+	collector.SetRedirectHandler(func(req *http.Request, via []*http.Request) (err error) {
+		if !c.scope.Allowed(req.URL) {
+			err = ErrOutOfScope
+
+			return
+		}
+
+		if session != nil && session.IsLogoutURL(req.URL) {
+			err = ErrLoggedOut
+
+			return
+		}
+
+		if len(via) >= 10 {
+			err = http.ErrUseLastResponse
+
+			return
+		}
+
+		if req.URL.Host != via[len(via)-1].URL.Host {
+			req.Header.Del("Authorization")
+		}
 
-				header := strings.TrimSpace(splitEntry[0])
-				value := splitEntry[1]
+		return
+	})
+
+	if c.cfg.Debug {
+		collector.SetDebugger(&debug.LogDebugger{})
+	}
+
+	if err = collector.SetStorage(store); err != nil {
+		return
+	}
+
+	// NOTE: Must come AFTER .SetStorage calls, which replace the client's jar with the storage's.
+	collector.SetCookieJar(c.jar)
+
+	return
+}
+
+func (c *Crawler) client(session *session, target string) (client *http.Client, err error) {
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(c.cfg.Timeout) * time.Second,
+		KeepAlive: time.Duration(c.cfg.Timeout) * time.Second,
+		Resolver:  c.resolver,
+	}
+
+	newTransport := func(config *tls.Config, entry *proxyEntry) (transport *http.Transport) {
+		transport = &http.Transport{
+			DialContext:         c.dialer(dialer.DialContext),
+			MaxIdleConns:        100,
+			MaxConnsPerHost:     1000,
+			IdleConnTimeout:     time.Duration(c.cfg.Timeout) * time.Second,
+			TLSHandshakeTimeout: time.Duration(c.cfg.Timeout) * time.Second,
+			TLSClientConfig:     config,
+		}
 
-				request.Headers.Set(header, value)
+		switch {
+		case entry == nil:
+		case entry.URL.Scheme == "socks5", entry.URL.Scheme == "socks5h":
+			transport.DialContext = c.socksDialer(entry, transport.DialContext)
+		default:
+			// NOTE: Proxies are set on the transport, as colly's SetProxyFunc replaces non *http.Transport transports.
+			transport.Proxy = http.ProxyURL(entry.URL)
+			transport.OnProxyConnectResponse = proxyConnectResponse
+
+			if entry.URL.Scheme == "https" {
+				transport.DialTLSContext = c.proxyTLSDialer(entry, transport.DialContext)
 			}
-		})
+		}
+
+		return
 	}
 
-	extensions.Referer(collector)
+	hostTLSConfigs := c.hostTLSConfigs
+
+	if c.cfg.TLS.ServerName != "" && target != "" {
+		var serverName hostTLSConfig
+
+		serverName, err = newServerNameTLSConfig(c.tlsConfig, c.hostTLSConfigs, target, c.cfg.TLS.ServerName)
+		if err != nil {
+			return
+		}
+
+		hostTLSConfigs = append([]hostTLSConfig{serverName}, c.hostTLSConfigs...)
+	}
+
+	newHostTransport := func(entry *proxyEntry) (transport http.RoundTripper) {
+		transport = newTransport(c.tlsConfig, entry)
+
+		if len(hostTLSConfigs) == 0 {
+			return
+		}
 
-	HTTPTransport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   time.Duration(c.cfg.Timeout) * time.Second,
-			KeepAlive: time.Duration(c.cfg.Timeout) * time.Second,
-		}).DialContext,
-		MaxIdleConns:        100,
-		MaxConnsPerHost:     1000,
-		IdleConnTimeout:     time.Duration(c.cfg.Timeout) * time.Second,
-		TLSHandshakeTimeout: time.Duration(c.cfg.Timeout) * time.Second,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-			Renegotiation:      tls.RenegotiateOnceAsClient,
+		hosts := make([]hostTransport, 0, len(hostTLSConfigs))
+
+		for _, host := range hostTLSConfigs {
+			hosts = append(hosts, hostTransport{
+				rule:      host.rule,
+				transport: newTransport(host.config, entry),
+			})
+		}
+
+		transport = &tlsTransport{
+			transport: transport,
+			hosts:     hosts,
+		}
+
+		return
+	}
+
+	var transport http.RoundTripper
+
+	switch {
+	case c.archive != nil:
+		// NOTE: Replaying, responses are served from the archive, without network access.
+		transport = &replayTransport{
+			archive: c.archive,
+		}
+	case c.proxyPool != nil || len(c.proxyRoutes) > 0:
+		transports := map[*proxyEntry]http.RoundTripper{}
+
+		for _, entry := range c.proxies {
+			transports[entry] = newHostTransport(entry)
+		}
+
+		transport = &proxyTransport{
+			direct:     newHostTransport(nil),
+			transports: transports,
+			route:      c.routeProxy,
+		}
+	default:
+		transport = newHostTransport(nil)
+	}
+
+	if c.har != nil {
+		transport = &harTransport{
+			transport: transport,
+			writer:    c.har,
+			bodies:    c.cfg.HAR.Bodies,
+			limit:     c.cfg.HAR.BodyLimit,
+		}
+	}
+
+	// NOTE: Replayed responses are already archived, so are not recorded again.
+	if len(c.cfg.Recorders) > 0 && c.archive == nil {
+		transport = &recordTransport{
+			transport: transport,
+			recorders: c.cfg.Recorders,
+			limit:     c.cfg.RecordBodyLimit,
+		}
+	}
+
+	if len(c.hostHeaders) > 0 {
+		transport = &headerTransport{
+			transport: transport,
+			headers:   c.hostHeaders,
+		}
+	}
+
+	if session != nil {
+		transport = &sessionTransport{
+			transport: transport,
+			session:   session,
+		}
+	}
+
+	client = &http.Client{
+		Transport: &limitedTransport{
+			transport: transport,
+			limiter:   c.limiter,
 		},
 	}
 
-	HTTPClient := &http.Client{
-		Transport: HTTPTransport,
+	return
+}
+
+func (c *Crawler) newStorage(target string) (store storage.Storage, err error) {
+	store = &storage.InMemoryStorage{}
+
+	if c.cfg.StateDirectory != "" {
+		name := "state.db"
+
+		if target != "" {
+			sum := sha256.Sum256([]byte(target))
+
+			name = fmt.Sprintf("state-%x.db", sum[:8])
+		}
+
+		store = &PersistentStorage{
+			Path: filepath.Join(c.cfg.StateDirectory, name),
+		}
 	}
 
-	// NOTE: Must come BEFORE .SetClient calls
-	collector.SetClient(HTTPClient)
+	err = store.Init()
 
-	// NOTE: Must come AFTER .SetClient calls
-	if len(c.cfg.Proxies) > 0 {
-		var rrps colly.ProxyFunc
+	return
+}
 
-		rrps, err = proxy.RoundRobinProxySwitcher(c.cfg.Proxies...)
-		if err != nil {
+func (c *Crawler) ExportCookies(w io.Writer) (err error) {
+	err = c.jar.Export(w)
+
+	return
+}
+
+func (c *Crawler) Close() (err error) {
+	if c.cfg.StateDirectory != "" {
+		if err = c.SaveCookies(filepath.Join(c.cfg.StateDirectory, "cookies.txt")); err != nil {
 			return
 		}
+	}
 
-		collector.SetProxyFunc(rrps)
+	if c.har != nil {
+		if err = c.har.Close(); err != nil {
+			return
+		}
 	}
 
-	if c.cfg.Debug {
-		collector.SetDebugger(&debug.LogDebugger{})
+	if closer, ok := c.storage.(io.Closer); ok {
+		err = closer.Close()
+	}
+
+	return
+}
+
+func (c *Crawler) loadCookies(name string) (err error) {
+	var file *os.File
+
+	file, err = os.Open(name)
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	if err = c.jar.Import(file); err != nil {
+		err = fmt.Errorf("error importing cookies from %s: %w", name, err)
+	}
+
+	return
+}
+
+func (c *Crawler) SaveCookies(name string) (err error) {
+	if err = c.jar.Save(name); err != nil {
+		err = fmt.Errorf("error exporting cookies to %s: %w", name, err)
+	}
+
+	return
+}
+
+func parseHeader(entry string) (header, value string, ok bool) {
+	var splitEntry []string
+
+	switch {
+	case strings.Contains(entry, ": "):
+		splitEntry = strings.SplitN(entry, ": ", 2)
+	case strings.Contains(entry, ":"):
+		splitEntry = strings.SplitN(entry, ":", 2)
+	default:
+		return
 	}
 
-	collector.SetStorage(c.storage)
+	header = strings.TrimSpace(splitEntry[0])
+	value = splitEntry[1]
+
+	ok = true
+
+	return
+}
+
+func isAlreadyVisited(err error) (visited bool) {
+	var alreadyVisitedError *colly.AlreadyVisitedError
+
+	visited = errors.As(err, &alreadyVisitedError)
 
 	return
 }
 
 func (c *Crawler) validate(URL string) (valid bool) {
-	valid = c._URLFilterRegex.MatchString(URL)
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return
+	}
+
+	valid = c.scope.Allowed(parsed)
 
 	return
 }
 
 type Result struct {
-	Type  ResultType
-	Value string
-	Error error
+	Type          ResultType
+	Value         string
+	Source        ResultSource
+	Referer       string
+	Depth         int
+	Tag           string
+	Attribute     string
+	LastModified  string
+	StatusCode    int
+	ContentType   string
+	ContentLength int64
+	Attempts      int
+	Form          *Form
+	Parameter     string
+	ErrorClass    string
+	Error         error
 }
 
 type ResultType int
 
+type ResultSource string
+
+type seed struct {
+	URL    string
+	Source ResultSource
+}
+
 type Configuration struct {
-	Domains           []string
-	IncludeSubdomains bool
-	Delay             int
-	Headers           []string
-	Timeout           int
-	Proxies           []string
-	Depth             int
-	Parallelism       int
-	Debug             bool
+	Domains             []string
+	IncludeSubdomains   bool
+	Scope               Scope
+	ReportOutOfScope    bool
+	Delay               int
+	RateLimit           float64
+	RateLimitPerHost    float64
+	Retry               Retry
+	Headers             []string
+	HostHeaders         map[string][]string
+	Timeout             int
+	TLS                 TLS
+	Resolves            []string
+	Resolvers           []string
+	Proxies             []string
+	ProxyRotation       string
+	ProxyPools          map[string][]string
+	ProxyRoutes         []ProxyRoute
+	Depth               int
+	Parallelism         int
+	Debug               bool
+	StateDirectory      string
+	SharedStorage       bool
+	CookieFiles         []string
+	HAR                 HAR
+	Recorders           []Recorder
+	RecordBodyLimit     int
+	Replay              string
+	Login               *Login
+	RespectRobots       bool
+	SitemapPaths        []string
+	SourceMapsDirectory string
+	SubmitForms         bool
+	PatternDedupe       bool
+	PatternVariants     int
 }
 
 var (
@@ -382,44 +1485,160 @@ var (
 const (
 	ResultURL ResultType = iota
 	ResultError
+	ResultFile
+	ResultForm
+	ResultParameter
+	ResultOutOfScope
+	ResultHostParameter
+)
+
+const (
+	ResultSourceHref      ResultSource = "href"
+	ResultSourceSrc       ResultSource = "src"
+	ResultSourceBody      ResultSource = "body"
+	ResultSourceRobots    ResultSource = "robots"
+	ResultSourceSitemap   ResultSource = "sitemap"
+	ResultSourceJS        ResultSource = "js"
+	ResultSourceSourceMap ResultSource = "sourcemap"
+	ResultSourceForm      ResultSource = "form"
+	ResultSourceJSON      ResultSource = "json"
+	ResultSourceArchive   ResultSource = "archive"
 )
 
 func New(cfg *Configuration) (crawler *Crawler, err error) {
 	crawler = &Crawler{
-		cfg: cfg,
+		cfg:     cfg,
+		headers: http.Header{},
 	}
 
-	URLFilterRegexPattern := `https?://([a-z0-9-]+\.)(?:[a-z0-9-]+\.)+[a-z]{2,}(:\d+)?(?:/[^?\s#]*)?(?:\?[^#\s]*)?(?:#[^\s]*)?`
+	for _, entry := range cfg.Headers {
+		if _, _, _, ok := parseHostHeader(entry); ok {
+			continue
+		}
 
-	if cfg.Domains != nil {
-		var b strings.Builder
+		header, value, ok := parseHeader(entry)
+		if !ok {
+			continue
+		}
 
-		b.WriteString("(?:")
+		crawler.headers.Set(header, value)
+	}
 
-		for i, s := range cfg.Domains {
-			if i != 0 {
-				b.WriteByte('|')
-			}
+	crawler.hostHeaders, err = newHostHeaders(cfg.Headers, cfg.HostHeaders)
+	if err != nil {
+		return
+	}
+
+	crawler._URLExtractorRegex = hqgourlextractor.New().CompileRegex()
+
+	crawler.scope, err = newScope(cfg)
+	if err != nil {
+		return
+	}
+
+	crawler.tlsConfig, crawler.hostTLSConfigs, err = newTLSConfigs(cfg.TLS)
+	if err != nil {
+		return
+	}
+
+	crawler.resolves, err = parseResolves(cfg.Resolves)
+	if err != nil {
+		return
+	}
+
+	crawler.resolver, err = newResolver(cfg.Resolvers)
+	if err != nil {
+		return
+	}
+
+	if cfg.ProxyRotation == "" {
+		cfg.ProxyRotation = ProxyRotationRoundRobin
+	}
+
+	if !slices.Contains(ProxyRotations, cfg.ProxyRotation) {
+		err = fmt.Errorf("error parsing proxy rotation %s: %w", cfg.ProxyRotation, ErrUnknownProxyRotation)
+
+		return
+	}
+
+	crawler.proxies = map[string]*proxyEntry{}
 
-			b.WriteString(regexp.QuoteMeta(s))
+	if len(cfg.Proxies) > 0 {
+		crawler.proxyPool, err = newProxyPool(cfg.Proxies, cfg.ProxyRotation, crawler.proxies)
+		if err != nil {
+			return
 		}
+	}
+
+	crawler.proxyRoutes, err = newProxyRoutes(cfg.ProxyRoutes, cfg.ProxyPools, cfg.ProxyRotation, crawler.proxies)
+	if err != nil {
+		return
+	}
+
+	probeDialer := &net.Dialer{
+		Timeout: time.Duration(cfg.Timeout) * time.Second,
+	}
+
+	for _, entry := range crawler.proxies {
+		entry.probe = crawler.proxyProbe(entry, crawler.dialer(probeDialer.DialContext))
+	}
+
+	crawler.limiter = newLimiter(cfg.RateLimit, cfg.RateLimitPerHost)
+
+	if err = cfg.Retry.validate(); err != nil {
+		return
+	}
+
+	crawler.jar, err = newCookieJar()
+	if err != nil {
+		return
+	}
 
-		b.WriteByte(')')
+	// NOTE: Cookies saved by a previous run are loaded first, so explicitly imported ones take precedence.
+	if cfg.StateDirectory != "" {
+		err = crawler.loadCookies(filepath.Join(cfg.StateDirectory, "cookies.txt"))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
 
-		URLFilterRegexPattern = fmt.Sprintf(`https?://(www\.)?%s(:\d+)?(?:/[^?\s#]*)?(?:\?[^#\s]*)?(?:#[^\s]*)?`, b.String())
+		err = nil
+	}
 
-		if cfg.IncludeSubdomains {
-			URLFilterRegexPattern = fmt.Sprintf(`https?://([a-z0-9-]+\.)*%s(:\d+)?(?:/[^?\s#]*)?(?:\?[^#\s]*)?(?:#[^\s]*)?`, b.String())
+	for _, name := range cfg.CookieFiles {
+		if err = crawler.loadCookies(name); err != nil {
+			return
 		}
 	}
 
-	crawler._URLFilterRegex = regexp.MustCompile(URLFilterRegexPattern)
-	crawler._URLExtractorRegex = hqgourlextractor.New().CompileRegex()
+	if cfg.StateDirectory != "" {
+		crawler.jar.persist = filepath.Join(cfg.StateDirectory, "cookies.txt")
+	}
 
 	crawler.fileURLsToRequestExtRegex = regexp.MustCompile(`\.(css|csv|js|json|map|txt|xml|yaml|yml)$`)
 	crawler.fileURLsNotToRequextExtRegex = regexp.MustCompile(`\.(apng|bpm|png|bmp|gif|heif|ico|cur|jpg|jpeg|jfif|pjp|pjpeg|psd|raw|svg|tif|tiff|webp|xbm|3gp|aac|flac|mpg|mpeg|mp3|mp4|m4a|m4v|m4p|oga|ogg|ogv|mov|wav|webm|eot|woff|woff2|ttf|otf)$`)
 
-	crawler.storage = &storage.InMemoryStorage{}
+	if cfg.SharedStorage {
+		crawler.storage, err = crawler.newStorage("")
+		if err != nil {
+			return
+		}
+	}
+
+	if cfg.Replay != "" {
+		crawler.archive, err = newArchive(cfg.Replay)
+		if err != nil {
+			return
+		}
+	}
+
+	if cfg.HAR.File != "" {
+		crawler.har, err = newHARWriter(cfg.HAR.File)
+		if err != nil {
+			err = fmt.Errorf("error creating HAR %s: %w", cfg.HAR.File, err)
+
+			return
+		}
+	}
 
 	return
 }
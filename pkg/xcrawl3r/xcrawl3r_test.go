@@ -0,0 +1,241 @@
+package xcrawl3r
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCrawlDepth(t *testing.T) {
+	pages := map[string]string{
+		"/":           `<a href="/a.html?x=1">a</a><form action="/search"><input name="q"></form><script src="/app.min.js"></script><script src="/app2.js"></script>`,
+		"/a.html":     `<a href="/b.html">b</a><a href="/">home</a>`,
+		"/b.html":     `<a href="/c.html">c</a><a href="/a.html?x=1">a</a>`,
+		"/c.html":     `c`,
+		"/app.min.js": `fetch("/api/orders")`,
+		"/app2.js":    `fetch("/api/orders")`,
+		"/api/orders": `{}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/app.min.js", "/app2.js":
+			w.Header().Set("Content-Type", "text/javascript")
+		case "/api/orders":
+			w.Header().Set("Content-Type", "application/json")
+		default:
+			w.Header().Set("Content-Type", "text/html")
+		}
+
+		w.Write([]byte(page))
+	}))
+
+	defer server.Close()
+
+	crawler, err := New(&Configuration{
+		Domains:        []string{"127.0.0.1"},
+		Timeout:        10,
+		Depth:          3,
+		Parallelism:    2,
+		StateDirectory: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer crawler.Close()
+
+	type key struct {
+		Type      ResultType
+		Value     string
+		Parameter string
+	}
+
+	depths := map[key]int{}
+	statuses := map[key]int{}
+
+	for result := range crawler.Crawl(server.URL) {
+		if result.Type == ResultError {
+			continue
+		}
+
+		k := key{result.Type, result.Value, result.Parameter}
+
+		if _, ok := depths[k]; ok {
+			t.Errorf("result %+v reported more than once", k)
+		}
+
+		depths[k] = result.Depth
+		statuses[k] = result.StatusCode
+	}
+
+	tests := []struct {
+		key    key
+		depth  int
+		status int
+	}{
+		{key{ResultURL, server.URL + "/a.html?x=1", ""}, 1, http.StatusOK},
+		{key{ResultURL, server.URL + "/app.min.js", ""}, 1, http.StatusOK},
+		{key{ResultURL, server.URL + "/api/orders", ""}, 2, http.StatusOK},
+		{key{ResultURL, server.URL + "/b.html", ""}, 2, http.StatusOK},
+		{key{ResultURL, server.URL + "/c.html", ""}, 3, 0},
+		{key{ResultForm, server.URL + "/search", ""}, 1, 0},
+		{key{ResultParameter, server.URL + "/a.html", "x"}, 1, 0},
+		{key{ResultParameter, server.URL + "/search", "q"}, 1, 0},
+	}
+
+	for _, test := range tests {
+		depth, ok := depths[test.key]
+		if !ok {
+			t.Errorf("result %+v not reported", test.key)
+
+			continue
+		}
+
+		if depth != test.depth {
+			t.Errorf("result %+v depth = %d, want %d", test.key, depth, test.depth)
+		}
+
+		if statuses[test.key] != test.status {
+			t.Errorf("result %+v status = %d, want %d", test.key, statuses[test.key], test.status)
+		}
+	}
+}
+
+func TestCrawlSourceMap(t *testing.T) {
+	pages := map[string]string{
+		"/":           `<script src="/app.js"></script>`,
+		"/app.js":     "var a;\n//# sourceMappingURL=app.js.map",
+		"/app.js.map": `{"version":3,"sources":["webpack:///./src/client.ts"],"sourcesContent":["fetch('/api/hidden')"]}`,
+		"/api/hidden": `{}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Write([]byte(page))
+	}))
+
+	defer server.Close()
+
+	directory := t.TempDir()
+
+	crawler, err := New(&Configuration{
+		Domains:             []string{"127.0.0.1"},
+		Timeout:             10,
+		Depth:               3,
+		Parallelism:         2,
+		StateDirectory:      t.TempDir(),
+		SourceMapsDirectory: directory,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer crawler.Close()
+
+	var file, endpoint *Result
+
+	for result := range crawler.Crawl(server.URL) {
+		switch {
+		case result.Type == ResultFile:
+			file = &result
+		case result.Type == ResultURL && result.Value == server.URL+"/api/hidden":
+			endpoint = &result
+		}
+	}
+
+	if file == nil || file.Value != "webpack:///./src/client.ts" || file.Referer != server.URL+"/app.js.map" {
+		t.Errorf("file result = %+v, want webpack:///./src/client.ts from %s/app.js.map", file, server.URL)
+	}
+
+	if endpoint == nil || endpoint.Source != ResultSourceSourceMap || endpoint.StatusCode != http.StatusOK {
+		t.Errorf("endpoint result = %+v, want %s/api/hidden from the source map", endpoint, server.URL)
+	}
+
+	host := sanitizeHost(strings.TrimPrefix(server.URL, "http://"))
+
+	if content, err := os.ReadFile(filepath.Join(directory, host, "src", "client.ts")); err != nil || string(content) != "fetch('/api/hidden')" {
+		t.Errorf("reconstructed source = %q, %v, want %q", content, err, "fetch('/api/hidden')")
+	}
+}
+
+func TestCrawlSubmitForms(t *testing.T) {
+	var mutex sync.Mutex
+
+	requested := map[string]bool{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+
+		requested[r.Method+" "+r.URL.RequestURI()] = true
+
+		mutex.Unlock()
+
+		w.Header().Set("Content-Type", "text/html")
+
+		if r.URL.Path == "/" {
+			w.Write([]byte(`<form action="/search"><input name="q"><input type="hidden" name="csrf" value="abc"></form><form method="post" action="/login"><input name="user"></form>`))
+		}
+	}))
+
+	defer server.Close()
+
+	for _, submit := range []bool{false, true} {
+		clear(requested)
+
+		crawler, err := New(&Configuration{
+			Domains:        []string{"127.0.0.1"},
+			Timeout:        10,
+			Depth:          2,
+			Parallelism:    2,
+			StateDirectory: t.TempDir(),
+			SubmitForms:    submit,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		forms := map[string]string{}
+
+		for result := range crawler.Crawl(server.URL) {
+			if result.Type == ResultForm {
+				forms[result.Value] = result.Form.Method
+			}
+		}
+
+		crawler.Close()
+
+		if forms[server.URL+"/search"] != http.MethodGet || forms[server.URL+"/login"] != http.MethodPost {
+			t.Errorf("forms = %v, want GET /search and POST /login", forms)
+		}
+
+		mutex.Lock()
+
+		if requested["GET /search?csrf=abc&q=xcrawl3r"] != submit {
+			t.Errorf("with SubmitForms %t, GET form submitted = %t", submit, !submit)
+		}
+
+		if requested["POST /login"] {
+			t.Errorf("with SubmitForms %t, POST form submitted", submit)
+		}
+
+		mutex.Unlock()
+	}
+}